@@ -1,14 +1,29 @@
 package main
 
 import (
+	"chat-be/internal/cache"
 	"chat-be/internal/config"
 	"chat-be/internal/database"
 	"chat-be/internal/delivery/http/handlers"
 	"chat-be/internal/delivery/http/router"
+	"chat-be/internal/domain/entities"
 	"chat-be/internal/domain/repositories"
 	"chat-be/internal/kafka"
+	"chat-be/internal/mailer"
+	"chat-be/internal/oidc"
+	"chat-be/internal/push"
 	"chat-be/internal/usecases"
+	"chat-be/internal/webhook"
+	"chat-be/package/logging"
 	"chat-be/package/middleware"
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -26,37 +41,277 @@ func main() {
 	messageRepo := repositories.NewMessageRepository(db)
 	socketPathRepo := repositories.NewSocketPathRepository(db)
 	chatRoomRepo := repositories.NewChatRoomRepository(db)
+	passwordResetRepo := repositories.NewPasswordResetRepository(db)
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(db)
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(db)
+	sessionRepo := repositories.NewSessionRepository(db)
+	identityRepo := repositories.NewExternalIdentityRepository(db)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
+	deviceTokenRepo := repositories.NewDeviceTokenRepository(db)
+	roomInviteRepo := repositories.NewRoomInviteRepository(db)
+	joinRequestRepo := repositories.NewJoinRequestRepository(db)
+	pinnedMessageRepo := repositories.NewPinnedMessageRepository(db)
+	contactRepo := repositories.NewContactRepository(db)
+	blockRepo := repositories.NewBlockRepository(db)
+	reportRepo := repositories.NewReportRepository(db)
+	channelFanOutRepo := repositories.NewChannelFanOutRepository(db)
+
+	// Initialize real-time event publisher for the WebSocket gateway
+	eventPublisher := kafka.NewKafkaPublisher()
+
+	// Initialize offline notification store, kept in memory unless Redis is configured
+	notificationStore := cache.NewMemoryNotificationStore()
+	if redisAddr := config.GetEnv("REDIS_ADDR", ""); redisAddr != "" {
+		redisDB, _ := strconv.Atoi(config.GetEnv("REDIS_DB", "0"))
+		notificationStore = cache.NewRedisNotificationStore(redis.NewClient(&redis.Options{
+			Addr:     redisAddr,
+			Password: config.GetEnv("REDIS_PASSWORD", ""),
+			DB:       redisDB,
+		}))
+	}
+	notificationPublisher := kafka.NewKafkaNotificationPublisher()
+
+	// Initialize push providers, a platform without credentials gets no push notifications
+	pushProviders := map[string]push.Provider{}
+	if credentialsFile := config.GetEnv("FCM_CREDENTIALS_FILE", ""); credentialsFile != "" {
+		fcmConfig, err := push.LoadFCMConfig(credentialsFile)
+		if err != nil {
+			log.Fatalf("Failed to load FCM credentials: %v", err)
+		}
+		fcmProvider := push.NewFCMProvider(fcmConfig)
+		pushProviders[entities.PlatformAndroid] = fcmProvider
+		pushProviders[entities.PlatformWeb] = fcmProvider
+	}
+	if keyFile := config.GetEnv("APNS_KEY_FILE", ""); keyFile != "" {
+		keyPEM, err := os.ReadFile(keyFile)
+		if err != nil {
+			log.Fatalf("Failed to read APNs key: %v", err)
+		}
+		apnsProvider, err := push.NewAPNsProvider(push.APNsConfig{
+			KeyID:   config.GetEnv("APNS_KEY_ID", ""),
+			TeamID:  config.GetEnv("APNS_TEAM_ID", ""),
+			Topic:   config.GetEnv("APNS_TOPIC", ""),
+			Sandbox: config.GetEnv("APNS_SANDBOX", "false") == "true",
+		}, keyPEM)
+		if err != nil {
+			log.Fatalf("Failed to initialize APNs: %v", err)
+		}
+		pushProviders[entities.PlatformIOS] = apnsProvider
+	}
+
+	// Initialize Mailer
+	outboxMailer := mailer.NewOutboxMailer(config.GetEnv("MAIL_OUTBOX_PATH", "outbox.log"))
+
+	// Initialize single sign-on, disabled unless an issuer is configured
+	var ssoProvider *oidc.Provider
+	if issuerURL := config.GetEnv("OIDC_ISSUER_URL", ""); issuerURL != "" {
+		ssoProvider = oidc.NewProvider(oidc.Config{
+			IssuerURL:    issuerURL,
+			ClientID:     config.GetEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: config.GetEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  config.GetEnv("OIDC_REDIRECT_URL", "http://localhost:3000/sso/callback"),
+		})
+	}
 
 	// Initialize Usecases
-	userUsecase := usecases.NewUserUsecase(userRepo, socketPathRepo)
-	messageUsecase := usecases.NewMessageUsecase(chatRoomRepo, messageRepo, userRepo)
-	chatRoomUsecase := usecases.NewChatRoomUsecase(chatRoomRepo, userRepo)
+	userUsecase := usecases.NewUserUsecase(userRepo, socketPathRepo, passwordResetRepo, loginAttemptRepo, recoveryCodeRepo, sessionRepo, identityRepo, outboxMailer, ssoProvider)
+	webhookUsecase := usecases.NewWebhookUsecase(webhookRepo, chatRoomRepo)
+	messageUsecase := usecases.NewMessageUsecase(chatRoomRepo, messageRepo, userRepo, blockRepo, channelFanOutRepo, eventPublisher, webhookUsecase, notificationPublisher)
+	chatRoomUsecase := usecases.NewChatRoomUsecase(chatRoomRepo, userRepo, messageRepo, roomInviteRepo, joinRequestRepo, pinnedMessageRepo, contactRepo, blockRepo, eventPublisher, webhookUsecase, notificationPublisher)
+	sessionUsecase := usecases.NewSessionUsecase(sessionRepo)
+	presenceUsecase := usecases.NewPresenceUsecase(userRepo, eventPublisher)
+	typingUsecase := usecases.NewTypingUsecase(chatRoomRepo, eventPublisher)
+	pushUsecase := usecases.NewPushUsecase(deviceTokenRepo, userRepo, chatRoomRepo, pushProviders)
+	notificationUsecase := usecases.NewNotificationUsecase(userRepo, notificationStore, eventPublisher, pushUsecase)
+	contactUsecase := usecases.NewContactUsecase(contactRepo, userRepo, blockRepo, eventPublisher, notificationPublisher)
+	moderationUsecase := usecases.NewModerationUsecase(blockRepo, reportRepo, contactRepo, userRepo, messageRepo, chatRoomRepo)
+	botUsecase := usecases.NewBotUsecase(userRepo, socketPathRepo, apiKeyRepo)
+
+	// Reject tokens whose session was revoked and let bots authenticate with API keys
+	middleware.SetSessionValidator(sessionUsecase.ValidateSession)
+	middleware.SetAPIKeyAuthenticator(botUsecase.AuthenticateAPIKey)
+	if err := middleware.SetTrustedProxies(strings.Split(config.GetEnv("TRUSTED_PROXIES", ""), ",")); err != nil {
+		log.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
 
 	// Initialize Handlers
 	userHandler := handlers.NewUserHandler(userUsecase)
 	messageHandler := handlers.NewMessageHandler(messageUsecase)
 	chatRoomHandler := handlers.NewChatRoomHandler(chatRoomUsecase)
+	sessionHandler := handlers.NewSessionHandler(sessionUsecase)
+	botHandler := handlers.NewBotHandler(botUsecase)
+	webhookHandler := handlers.NewWebhookHandler(webhookUsecase)
+	pushHandler := handlers.NewPushHandler(pushUsecase)
+	contactHandler := handlers.NewContactHandler(contactUsecase)
+	moderationHandler := handlers.NewModerationHandler(moderationUsecase)
 
-	kafkaService := kafka.NewKafkaService(messageUsecase)
+	kafkaService := kafka.NewKafkaService(messageUsecase, presenceUsecase, typingUsecase, notificationUsecase)
+	notificationService := kafka.NewNotificationService(notificationUsecase)
 
 	go kafkaService.ConsumeMessage()
+	go notificationService.ConsumeNotifications()
+
+	// Send queued webhook deliveries in the background
+	webhookWorker := webhook.NewWorker(webhookRepo)
+	go webhookWorker.Run(context.Background())
+
+	// Write the receipts of channel posts in the background
+	go func() {
+		for range time.Tick(2 * time.Second) {
+			if err := messageUsecase.ProcessChannelFanOuts(); err != nil {
+				logging.Log.Errorf("Failed to process channel fan-outs: %v", err)
+			}
+		}
+	}()
+
+	// Mark users offline when the gateway stops sending heartbeats without a disconnect
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := presenceUsecase.ExpireStale(); err != nil {
+				logging.Log.Errorf("Failed to expire stale presence: %v", err)
+			}
+		}
+	}()
 
 	httpRouter := router.NewMuxRouter()
 	httpRouter.POST("/api/users/login", userHandler.Login)
 	httpRouter.OPTIONS("/api/users/login")
+	httpRouter.POST("/api/users/login/2fa", userHandler.LoginTwoFactor)
+	httpRouter.OPTIONS("/api/users/login/2fa")
+	httpRouter.GET("/api/users/sso/login", userHandler.StartSSOLogin)
+	httpRouter.OPTIONS("/api/users/sso/login")
+	httpRouter.POST("/api/users/sso/callback", userHandler.CompleteSSOLogin)
+	httpRouter.OPTIONS("/api/users/sso/callback")
 	httpRouter.POST("/api/users/register", userHandler.Register)
 	httpRouter.OPTIONS("/api/users/register")
 	httpRouter.GETWithMiddleware("/api/users/search", userHandler.SearchUsers, middleware.AuthMiddleware)
 	httpRouter.OPTIONS("/api/users/search")
+	httpRouter.GETWithMiddleware("/api/users/me", userHandler.GetProfile, middleware.AuthMiddleware)
+	httpRouter.PATCHWithMiddleware("/api/users/me", userHandler.UpdateProfile, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me")
+	httpRouter.POSTWithMiddleware("/api/users/me/password", userHandler.ChangePassword, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/password")
+	httpRouter.POSTWithMiddleware("/api/users/me/2fa/enroll", userHandler.EnrollTwoFactor, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/2fa/enroll")
+	httpRouter.POSTWithMiddleware("/api/users/me/2fa/confirm", userHandler.ConfirmTwoFactor, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/2fa/confirm")
+	httpRouter.POSTWithMiddleware("/api/users/me/2fa/disable", userHandler.DisableTwoFactor, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/2fa/disable")
+	httpRouter.GETWithMiddleware("/api/users/me/sessions", sessionHandler.GetSessions, middleware.AuthMiddleware)
+	httpRouter.DELETEWithMiddleware("/api/users/me/sessions", sessionHandler.RevokeOtherSessions, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/sessions")
+	httpRouter.DELETEWithMiddleware("/api/users/me/sessions/{id}", sessionHandler.RevokeSession, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/sessions/{id}")
+	httpRouter.GETWithMiddleware("/api/users/me/devices", pushHandler.GetDevices, middleware.AuthMiddleware)
+	httpRouter.POSTWithMiddleware("/api/users/me/devices", pushHandler.RegisterDevice, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/devices")
+	httpRouter.DELETEWithMiddleware("/api/users/me/devices/{id}", pushHandler.RemoveDevice, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/devices/{id}")
+	httpRouter.GETWithMiddleware("/api/users/me/push-settings", pushHandler.GetPushSettings, middleware.AuthMiddleware)
+	httpRouter.PUTWithMiddleware("/api/users/me/push-settings", pushHandler.UpdatePushSettings, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/push-settings")
+	httpRouter.GETWithMiddleware("/api/users/me/privacy", userHandler.GetPrivacySettings, middleware.AuthMiddleware)
+	httpRouter.PUTWithMiddleware("/api/users/me/privacy", userHandler.UpdatePrivacySettings, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/privacy")
+	httpRouter.GETWithMiddleware("/api/users/me/blocks", moderationHandler.GetBlockedUsers, middleware.AuthMiddleware)
+	httpRouter.POSTWithMiddleware("/api/users/me/blocks", moderationHandler.BlockUser, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/blocks")
+	httpRouter.DELETEWithMiddleware("/api/users/me/blocks/{userId}", moderationHandler.UnblockUser, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/users/me/blocks/{userId}")
+	httpRouter.POSTWithMiddleware("/api/reports", moderationHandler.ReportUser, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/reports")
+	httpRouter.GETWithMiddleware("/api/contacts", contactHandler.GetContacts, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/contacts")
+	httpRouter.DELETEWithMiddleware("/api/contacts/{userId}", contactHandler.RemoveContact, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/contacts/{userId}")
+	httpRouter.GETWithMiddleware("/api/contacts/requests", contactHandler.GetRequests, middleware.AuthMiddleware)
+	httpRouter.POSTWithMiddleware("/api/contacts/requests", contactHandler.SendRequest, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/contacts/requests")
+	httpRouter.POSTWithMiddleware("/api/contacts/requests/{id}/accept", contactHandler.AcceptRequest, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/contacts/requests/{id}/accept")
+	httpRouter.POSTWithMiddleware("/api/contacts/requests/{id}/decline", contactHandler.DeclineRequest, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/contacts/requests/{id}/decline")
+	httpRouter.POST("/api/users/password/forgot", userHandler.ForgotPassword)
+	httpRouter.OPTIONS("/api/users/password/forgot")
+	httpRouter.POST("/api/users/password/reset", userHandler.ResetPassword)
+	httpRouter.OPTIONS("/api/users/password/reset")
+	httpRouter.GET("/api/users/verify-email", userHandler.VerifyEmail)
+	httpRouter.OPTIONS("/api/users/verify-email")
+	httpRouter.POST("/api/users/verify-email/resend", userHandler.ResendVerification)
+	httpRouter.OPTIONS("/api/users/verify-email/resend")
 	//message
-	httpRouter.GETWithMiddleware("/api/messages/history", messageHandler.GetMessageHistory, middleware.AuthMiddleware)
+	httpRouter.GETWithMiddleware("/api/messages/history", messageHandler.GetMessageHistory, middleware.AllowAPIKey(entities.ScopeMessagesRead), middleware.AuthMiddleware)
 	httpRouter.OPTIONS("/api/messages/history")
+	httpRouter.GETWithMiddleware("/api/messages/search", messageHandler.SearchMessages, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/messages/search")
+	httpRouter.POSTWithMiddleware("/api/messages", messageHandler.SendMessage, middleware.AllowAPIKey(entities.ScopeMessagesSend), middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/messages")
+
+	//bot
+	httpRouter.GETWithMiddleware("/api/bots", botHandler.GetBots, middleware.AuthMiddleware)
+	httpRouter.POSTWithMiddleware("/api/bots", botHandler.CreateBot, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/bots")
+	httpRouter.GETWithMiddleware("/api/bots/{id}/keys", botHandler.GetAPIKeys, middleware.AuthMiddleware)
+	httpRouter.POSTWithMiddleware("/api/bots/{id}/keys", botHandler.CreateAPIKey, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/bots/{id}/keys")
+	httpRouter.DELETEWithMiddleware("/api/bots/{id}/keys/{keyId}", botHandler.RevokeAPIKey, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/bots/{id}/keys/{keyId}")
 
 	//room
 	httpRouter.GETWithMiddleware("/api/rooms", chatRoomHandler.GetRooms, middleware.AuthMiddleware)
 	httpRouter.OPTIONS("/api/rooms")
 	httpRouter.POSTWithMiddleware("/api/rooms", chatRoomHandler.CreateRoom, middleware.AuthMiddleware)
 	httpRouter.OPTIONS("/api/rooms")
+
+	httpRouter.PATCHWithMiddleware("/api/rooms/{id}", chatRoomHandler.UpdateRoom, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}")
+	httpRouter.POSTWithMiddleware("/api/rooms/{id}/members", chatRoomHandler.AddMembers, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/members")
+	httpRouter.DELETEWithMiddleware("/api/rooms/{id}/members/{userId}", chatRoomHandler.RemoveMember, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/members/{userId}")
+	httpRouter.PUTWithMiddleware("/api/rooms/{id}/members/{userId}/role", chatRoomHandler.ChangeRole, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/members/{userId}/role")
+	httpRouter.POSTWithMiddleware("/api/rooms/{id}/leave", chatRoomHandler.LeaveRoom, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/leave")
+	httpRouter.GETWithMiddleware("/api/rooms/{id}/invites", chatRoomHandler.GetInvites, middleware.AuthMiddleware)
+	httpRouter.POSTWithMiddleware("/api/rooms/{id}/invites", chatRoomHandler.CreateInvite, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/invites")
+	httpRouter.DELETEWithMiddleware("/api/rooms/{id}/invites/{inviteId}", chatRoomHandler.RevokeInvite, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/invites/{inviteId}")
+	httpRouter.POSTWithMiddleware("/api/invites/{token}/join", chatRoomHandler.JoinByInvite, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/invites/{token}/join")
+	httpRouter.POSTWithMiddleware("/api/channels", chatRoomHandler.CreateChannel, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/channels")
+	httpRouter.POSTWithMiddleware("/api/channels/{id}/subscribe", chatRoomHandler.Subscribe, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/channels/{id}/subscribe")
+	httpRouter.GETWithMiddleware("/api/rooms/{id}/join-requests", chatRoomHandler.GetJoinRequests, middleware.AuthMiddleware)
+	httpRouter.POSTWithMiddleware("/api/rooms/{id}/join-requests", chatRoomHandler.RequestToJoin, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/join-requests")
+	httpRouter.POSTWithMiddleware("/api/rooms/{id}/join-requests/{requestId}/approve", chatRoomHandler.ApproveJoinRequest, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/join-requests/{requestId}/approve")
+	httpRouter.POSTWithMiddleware("/api/rooms/{id}/join-requests/{requestId}/reject", chatRoomHandler.RejectJoinRequest, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/join-requests/{requestId}/reject")
+	httpRouter.PUTWithMiddleware("/api/rooms/{id}/mute", pushHandler.MuteRoom, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/mute")
+	httpRouter.GETWithMiddleware("/api/rooms/{id}/pins", chatRoomHandler.GetPinnedMessages, middleware.AuthMiddleware)
+	httpRouter.POSTWithMiddleware("/api/rooms/{id}/pins", chatRoomHandler.PinMessage, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/pins")
+	httpRouter.DELETEWithMiddleware("/api/rooms/{id}/pins/{messageId}", chatRoomHandler.UnpinMessage, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/pins/{messageId}")
+	httpRouter.PUTWithMiddleware("/api/rooms/{id}/pin", chatRoomHandler.PinRoom, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/pin")
+	httpRouter.PUTWithMiddleware("/api/rooms/{id}/archive", chatRoomHandler.ArchiveRoom, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/archive")
+
+	//webhook
+	httpRouter.GETWithMiddleware("/api/rooms/{id}/webhooks", webhookHandler.GetWebhooks, middleware.AuthMiddleware)
+	httpRouter.POSTWithMiddleware("/api/rooms/{id}/webhooks", webhookHandler.CreateWebhook, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/webhooks")
+	httpRouter.DELETEWithMiddleware("/api/rooms/{id}/webhooks/{webhookId}", webhookHandler.DeleteWebhook, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/webhooks/{webhookId}")
+	httpRouter.GETWithMiddleware("/api/rooms/{id}/webhooks/{webhookId}/deliveries", webhookHandler.GetDeliveries, middleware.AuthMiddleware)
+	httpRouter.OPTIONS("/api/rooms/{id}/webhooks/{webhookId}/deliveries")
 	// Start Server
 	port := config.GetEnv("APP_PORT", ":8080")
 	httpRouter.SERVE(port)
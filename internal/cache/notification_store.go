@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"chat-be/internal/delivery/http/models"
+)
+
+// Older notifications are dropped once a user has this many pending
+const maxPendingNotifications = 500
+
+// NotificationStore keeps notifications for offline users until they reconnect
+type NotificationStore interface {
+	Push(ctx context.Context, notification models.Notification) error
+	// Flush returns the pending notifications of a user, oldest first, and removes them
+	Flush(ctx context.Context, userID string) ([]models.Notification, error)
+}
+
+type memoryNotificationStore struct {
+	mu      sync.Mutex
+	pending map[string][]models.Notification
+}
+
+// NewMemoryNotificationStore keeps notifications in process, used when Redis is not configured
+func NewMemoryNotificationStore() NotificationStore {
+	return &memoryNotificationStore{pending: map[string][]models.Notification{}}
+}
+
+func (s *memoryNotificationStore) Push(ctx context.Context, notification models.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := append(s.pending[notification.UserID], notification)
+	if len(pending) > maxPendingNotifications {
+		pending = pending[len(pending)-maxPendingNotifications:]
+	}
+	s.pending[notification.UserID] = pending
+	return nil
+}
+
+func (s *memoryNotificationStore) Flush(ctx context.Context, userID string) ([]models.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pending[userID]
+	delete(s.pending, userID)
+	return pending, nil
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Pending notifications of users that never come back are dropped after this long
+const notificationTTL = 7 * 24 * time.Hour
+
+type redisNotificationStore struct {
+	client *redis.Client
+}
+
+func NewRedisNotificationStore(client *redis.Client) NotificationStore {
+	return &redisNotificationStore{client: client}
+}
+
+func notificationKey(userID string) string {
+	return "notifications:" + userID
+}
+
+func (s *redisNotificationStore) Push(ctx context.Context, notification models.Notification) error {
+	value, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	key := notificationKey(notification.UserID)
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, key, value)
+		pipe.LTrim(ctx, key, -maxPendingNotifications, -1)
+		pipe.Expire(ctx, key, notificationTTL)
+		return nil
+	})
+	return err
+}
+
+func (s *redisNotificationStore) Flush(ctx context.Context, userID string) ([]models.Notification, error) {
+	key := notificationKey(userID)
+
+	// Read and delete atomically so a notification pushed meanwhile is kept for the next flush
+	var values *redis.StringSliceCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		values = pipe.LRange(ctx, key, 0, -1)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var notifications []models.Notification
+	for _, v := range values.Val() {
+		var notification models.Notification
+		if err := json.Unmarshal([]byte(v), &notification); err != nil {
+			continue
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
@@ -35,5 +35,41 @@ func InitDB() *gorm.DB {
 }
 
 func InitMigration(db *gorm.DB) {
-	db.AutoMigrate(&entities.User{}, &entities.Message{}, &entities.MessageStatus{}, &entities.ChatRoom{}, &entities.ChatRoomParticipant{}, &entities.SocketPath{})
+	// Participants used to allow duplicates, keep the earliest row and rebuild the index as unique
+	db.Exec(`DELETE FROM chat_room_participants AS p USING chat_room_participants AS o
+		WHERE o.chat_room_id = p.chat_room_id AND o.user_id = p.user_id
+		AND (o.joined_at, o.created_at, o.id) < (p.joined_at, p.created_at, p.id)`)
+	db.Exec(`DO $$ BEGIN
+		IF EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_participants_user_room' AND indexdef NOT LIKE 'CREATE UNIQUE%') THEN
+			DROP INDEX idx_participants_user_room;
+		END IF;
+	END $$`)
+
+	db.AutoMigrate(&entities.User{}, &entities.Message{}, &entities.MessageStatus{}, &entities.ChatRoom{}, &entities.ChatRoomParticipant{}, &entities.SocketPath{}, &entities.PasswordResetToken{}, &entities.LoginAttempt{}, &entities.RecoveryCode{}, &entities.Session{}, &entities.ExternalIdentity{}, &entities.SSOLoginState{}, &entities.APIKey{}, &entities.Webhook{}, &entities.WebhookDelivery{}, &entities.DeviceToken{}, &entities.RoomInvite{}, &entities.JoinRequest{}, &entities.PinnedMessage{}, &entities.Contact{}, &entities.UserBlock{}, &entities.Report{}, &entities.ReportEvidence{}, &entities.ChannelFanOut{})
+
+	// Full-text search over message content, kept in sync with MessageRepository.SearchMessages
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_messages_content_fts ON messages USING GIN (to_tsvector('simple', content))`)
+
+	// Trigram indexes serve the ILIKE filters on room names and usernames
+	db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_chat_rooms_name_trgm ON chat_rooms USING GIN (name gin_trgm_ops)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (username gin_trgm_ops)`)
+
+	// One contact row per pair of users, whichever of them sent the request
+	db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_contacts_pair ON contacts (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id))`)
+
+	// Groups created before roles existed get their creator as owner
+	db.Exec(`UPDATE chat_room_participants AS p SET role = 'owner'
+		FROM chat_rooms AS r
+		WHERE r.id = p.chat_room_id AND r.is_group AND r.created_by = p.user_id
+		AND NOT EXISTS (SELECT 1 FROM chat_room_participants AS o WHERE o.chat_room_id = p.chat_room_id AND o.role = 'owner')`)
+
+	// Groups without a known creator, or whose creator has left, get their earliest member as owner
+	db.Exec(`UPDATE chat_room_participants SET role = 'owner'
+		WHERE id IN (SELECT DISTINCT ON (p.chat_room_id) p.id
+			FROM chat_room_participants AS p
+			JOIN chat_rooms AS r ON r.id = p.chat_room_id
+			WHERE r.is_group
+			AND NOT EXISTS (SELECT 1 FROM chat_room_participants AS o WHERE o.chat_room_id = p.chat_room_id AND o.role = 'owner')
+			ORDER BY p.chat_room_id, p.joined_at, p.created_at, p.id)`)
 }
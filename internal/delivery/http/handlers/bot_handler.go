@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/usecases"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
+	"chat-be/package/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type BotHandler struct {
+	BotUsecase usecases.BotUsecase
+}
+
+func NewBotHandler(botUsecase usecases.BotUsecase) *BotHandler {
+	return &BotHandler{BotUsecase: botUsecase}
+}
+
+func (h *BotHandler) CreateBot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.CreateBotRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	bot, err := h.BotUsecase.CreateBot(user.UserID, request)
+	if err != nil {
+		logging.LogError(ctx, "Create bot error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "Bot created successfully", bot)
+}
+
+func (h *BotHandler) GetBots(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	bots, err := h.BotUsecase.GetBots(user.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Get bots error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to fetch bots", nil)
+		return
+	}
+
+	if bots == nil {
+		bots = []entities.UserResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Bots fetched successfully", bots)
+}
+
+func (h *BotHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	apiKey, err := h.BotUsecase.CreateAPIKey(user.UserID, mux.Vars(r)["id"], request)
+	if err != nil {
+		logging.LogError(ctx, "Create API key error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "API key created, store the key now as it will not be shown again", apiKey)
+}
+
+func (h *BotHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	apiKeys, err := h.BotUsecase.GetAPIKeys(user.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		logging.LogError(ctx, "Get API keys error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	if apiKeys == nil {
+		apiKeys = []models.APIKeyResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "API keys fetched successfully", apiKeys)
+}
+
+func (h *BotHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err := h.BotUsecase.RevokeAPIKey(user.UserID, vars["id"], vars["keyId"])
+	if err != nil {
+		logging.LogError(ctx, "Revoke API key error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "API key revoked successfully", nil)
+}
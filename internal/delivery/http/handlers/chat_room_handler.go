@@ -3,15 +3,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"chat-be/internal/delivery/http/models"
 	"chat-be/internal/usecases"
 	"chat-be/package/helper"
+	"chat-be/package/logging"
 	"chat-be/package/middleware"
+	"chat-be/package/validators"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
 )
 
 type ChatRoomHandler struct {
@@ -44,8 +48,13 @@ func (h *ChatRoomHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
 
 	room, err := h.ChatRoomUsecase.CreateRoom(user.UserID, request.UserIDs, request.IsGroup, request.RoomName)
 
+	if errors.Is(err, usecases.ErrContactRequired) || errors.Is(err, usecases.ErrUserBlocked) {
+		middleware.WriteResponse(w, http.StatusForbidden, err.Error(), nil)
+		return
+	}
 	if err != nil {
 		middleware.WriteResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
 	}
 
 	middleware.WriteResponse(w, http.StatusCreated, "Success create room", room)
@@ -72,7 +81,22 @@ func (h *ChatRoomHandler) GetRooms(w http.ResponseWriter, r *http.Request) {
 		limit = 10 // Default value
 	}
 
-	rooms, total, err := h.ChatRoomUsecase.GetRoomsForUser(user.UserID, page, limit)
+	query := r.URL.Query()
+	filter := models.RoomListFilter{
+		Archived:    query.Get("archived") == "true",
+		Name:        query.Get("name"),
+		Participant: query.Get("participant"),
+		Type:        query.Get("type"),
+		UnreadOnly:  query.Get("unread") == "true",
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	rooms, total, err := h.ChatRoomUsecase.GetRoomsForUser(user.UserID, filter, page, limit)
 
 	if err != nil {
 		middleware.WriteResponse(w, http.StatusInternalServerError, err.Error(), nil)
@@ -91,3 +115,483 @@ func (h *ChatRoomHandler) GetRooms(w http.ResponseWriter, r *http.Request) {
 
 	middleware.WriteResponse(w, http.StatusOK, "Success get rooms", response)
 }
+
+func (h *ChatRoomHandler) AddMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.AddMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	room, err := h.ChatRoomUsecase.AddMembers(user.UserID, mux.Vars(r)["id"], request.UserIDs)
+	if err != nil {
+		logging.LogError(ctx, "Add members error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Members added successfully", room)
+}
+
+func (h *ChatRoomHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err := h.ChatRoomUsecase.RemoveMember(user.UserID, vars["id"], vars["userId"])
+	if err != nil {
+		logging.LogError(ctx, "Remove member error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Member removed successfully", nil)
+}
+
+func (h *ChatRoomHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.ChangeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err := h.ChatRoomUsecase.ChangeRole(user.UserID, vars["id"], vars["userId"], request.Role)
+	if err != nil {
+		logging.LogError(ctx, "Change role error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Role updated successfully", nil)
+}
+
+func (h *ChatRoomHandler) LeaveRoom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	err := h.ChatRoomUsecase.LeaveRoom(user.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		logging.LogError(ctx, "Leave room error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Left room successfully", nil)
+}
+
+func (h *ChatRoomHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.UpdateRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	validators.RegisterCustomValidators(validate)
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	room, err := h.ChatRoomUsecase.UpdateRoom(user.UserID, mux.Vars(r)["id"], request)
+	if err != nil {
+		logging.LogError(ctx, "Update room error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Room updated successfully", room)
+}
+
+func (h *ChatRoomHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.CreateInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	invite, err := h.ChatRoomUsecase.CreateInvite(user.UserID, mux.Vars(r)["id"], request)
+	if err != nil {
+		logging.LogError(ctx, "Create invite error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "Invite created successfully", invite)
+}
+
+func (h *ChatRoomHandler) GetInvites(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	invites, err := h.ChatRoomUsecase.GetInvites(user.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		logging.LogError(ctx, "Get invites error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	if invites == nil {
+		invites = []models.InviteResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Invites fetched successfully", invites)
+}
+
+func (h *ChatRoomHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err := h.ChatRoomUsecase.RevokeInvite(user.UserID, vars["id"], vars["inviteId"])
+	if err != nil {
+		logging.LogError(ctx, "Revoke invite error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Invite revoked successfully", nil)
+}
+
+func (h *ChatRoomHandler) JoinByInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	room, err := h.ChatRoomUsecase.JoinByInvite(user.UserID, mux.Vars(r)["token"])
+	if errors.Is(err, usecases.ErrJoinRequestPending) {
+		middleware.WriteResponse(w, http.StatusAccepted, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		logging.LogError(ctx, "Join by invite error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Joined room successfully", room)
+}
+
+func (h *ChatRoomHandler) RequestToJoin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.CreateJoinRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	joinRequest, err := h.ChatRoomUsecase.RequestToJoin(user.UserID, mux.Vars(r)["id"], request)
+	if err != nil {
+		logging.LogError(ctx, "Request to join error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "Join request sent successfully", joinRequest)
+}
+
+func (h *ChatRoomHandler) GetJoinRequests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	joinRequests, err := h.ChatRoomUsecase.GetJoinRequests(user.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		logging.LogError(ctx, "Get join requests error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	if joinRequests == nil {
+		joinRequests = []models.JoinRequestResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Join requests fetched successfully", joinRequests)
+}
+
+func (h *ChatRoomHandler) ApproveJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.decideJoinRequest(w, r, true)
+}
+
+func (h *ChatRoomHandler) RejectJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.decideJoinRequest(w, r, false)
+}
+
+func (h *ChatRoomHandler) decideJoinRequest(w http.ResponseWriter, r *http.Request, approve bool) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	joinRequest, err := h.ChatRoomUsecase.DecideJoinRequest(user.UserID, vars["id"], vars["requestId"], approve)
+	if err != nil {
+		logging.LogError(ctx, "Decide join request error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Join request "+joinRequest.Status+" successfully", joinRequest)
+}
+
+func (h *ChatRoomHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.CreateChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	validators.RegisterCustomValidators(validate)
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	room, err := h.ChatRoomUsecase.CreateChannel(user.UserID, request)
+	if err != nil {
+		logging.LogError(ctx, "Create channel error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "Channel created successfully", room)
+}
+
+func (h *ChatRoomHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	room, err := h.ChatRoomUsecase.Subscribe(user.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		logging.LogError(ctx, "Subscribe channel error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Subscribed to channel successfully", room)
+}
+
+func (h *ChatRoomHandler) PinRoom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.PinRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	room, err := h.ChatRoomUsecase.PinRoom(user.UserID, mux.Vars(r)["id"], request.Pinned)
+	if err != nil {
+		logging.LogError(ctx, "Pin room error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Room updated successfully", room)
+}
+
+func (h *ChatRoomHandler) ArchiveRoom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.ArchiveRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	room, err := h.ChatRoomUsecase.ArchiveRoom(user.UserID, mux.Vars(r)["id"], request.Archived)
+	if err != nil {
+		logging.LogError(ctx, "Archive room error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Room updated successfully", room)
+}
+
+func (h *ChatRoomHandler) PinMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.PinMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	pin, err := h.ChatRoomUsecase.PinMessage(user.UserID, mux.Vars(r)["id"], request.MessageID)
+	if err != nil {
+		logging.LogError(ctx, "Pin message error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "Message pinned successfully", pin)
+}
+
+func (h *ChatRoomHandler) UnpinMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err := h.ChatRoomUsecase.UnpinMessage(user.UserID, vars["id"], vars["messageId"])
+	if err != nil {
+		logging.LogError(ctx, "Unpin message error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Message unpinned successfully", nil)
+}
+
+func (h *ChatRoomHandler) GetPinnedMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	pins, err := h.ChatRoomUsecase.GetPinnedMessages(user.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		logging.LogError(ctx, "Get pinned messages error: %v", err)
+		middleware.WriteResponse(w, roomErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	if pins == nil {
+		pins = []models.PinnedMessageResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Pinned messages fetched successfully", pins)
+}
+
+func roomErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrRoomPermission), errors.Is(err, usecases.ErrOwnerProtection):
+		return http.StatusForbidden
+	case errors.Is(err, usecases.ErrRoomNotFound), errors.Is(err, usecases.ErrMemberNotFound), errors.Is(err, usecases.ErrInvalidInvite):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
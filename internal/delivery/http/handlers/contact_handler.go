@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/usecases"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
+	"chat-be/package/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type ContactHandler struct {
+	ContactUsecase usecases.ContactUsecase
+}
+
+func NewContactHandler(contactUsecase usecases.ContactUsecase) *ContactHandler {
+	return &ContactHandler{ContactUsecase: contactUsecase}
+}
+
+func (h *ContactHandler) GetContacts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	contacts, err := h.ContactUsecase.GetContacts(user.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Get contacts error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to fetch contacts", nil)
+		return
+	}
+
+	if contacts == nil {
+		contacts = []models.ContactResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Contacts fetched successfully", contacts)
+}
+
+func (h *ContactHandler) RemoveContact(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	err := h.ContactUsecase.RemoveContact(user.UserID, mux.Vars(r)["userId"])
+	if err != nil {
+		logging.LogError(ctx, "Remove contact error: %v", err)
+		middleware.WriteResponse(w, contactErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Contact removed successfully", nil)
+}
+
+func (h *ContactHandler) SendRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.SendFriendRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	friendRequest, err := h.ContactUsecase.SendRequest(user.UserID, request.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Send friend request error: %v", err)
+		middleware.WriteResponse(w, contactErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "Friend request sent successfully", friendRequest)
+}
+
+func (h *ContactHandler) GetRequests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	requests, err := h.ContactUsecase.GetRequests(user.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Get friend requests error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to fetch friend requests", nil)
+		return
+	}
+
+	if requests == nil {
+		requests = []models.FriendRequestResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Friend requests fetched successfully", requests)
+}
+
+func (h *ContactHandler) AcceptRequest(w http.ResponseWriter, r *http.Request) {
+	h.respondRequest(w, r, true)
+}
+
+func (h *ContactHandler) DeclineRequest(w http.ResponseWriter, r *http.Request) {
+	h.respondRequest(w, r, false)
+}
+
+func (h *ContactHandler) respondRequest(w http.ResponseWriter, r *http.Request, accept bool) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	friendRequest, err := h.ContactUsecase.RespondRequest(user.UserID, mux.Vars(r)["id"], accept)
+	if err != nil {
+		logging.LogError(ctx, "Respond friend request error: %v", err)
+		middleware.WriteResponse(w, contactErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Friend request "+friendRequest.Status+" successfully", friendRequest)
+}
+
+func contactErrorStatus(err error) int {
+	if errors.Is(err, usecases.ErrFriendRequestNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
@@ -2,12 +2,17 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
 
 	"chat-be/internal/delivery/http/models"
 	"chat-be/internal/usecases"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
 	"chat-be/package/middleware"
+
+	"github.com/go-playground/validator/v10"
 )
 
 type MessageHandler struct {
@@ -35,6 +40,11 @@ func (h *MessageHandler) GetMessageHistory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if !user.AllowsRoom(roomID) {
+		middleware.WriteResponse(w, http.StatusForbidden, "API key is not allowed to access this room", nil)
+		return
+	}
+
 	// Parse limit and offset
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
@@ -66,3 +76,72 @@ func (h *MessageHandler) GetMessageHistory(w http.ResponseWriter, r *http.Reques
 
 	middleware.WriteResponse(w, http.StatusOK, "Chat history fetched", response)
 }
+
+func (h *MessageHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 20 // Default value
+	}
+
+	messages, nextCursor, err := h.MessageUsecase.SearchMessages(user.UserID, query.Get("q"), query.Get("room_id"), query.Get("cursor"), limit)
+	if err != nil {
+		logging.LogError(ctx, "Search messages error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if messages == nil {
+		messages = []models.MessageSearchResponse{}
+	}
+
+	response := map[string]interface{}{
+		"messages":    messages,
+		"next_cursor": nextCursor,
+		"limit":       limit,
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Messages fetched successfully", response)
+}
+
+func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	if !user.AllowsRoom(request.RoomID) {
+		middleware.WriteResponse(w, http.StatusForbidden, "API key is not allowed to access this room", nil)
+		return
+	}
+
+	message, err := h.MessageUsecase.SendMessage(user.UserID, request.RoomID, request.Content)
+	if err != nil {
+		logging.LogError(ctx, "Send message error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "Message sent", message)
+}
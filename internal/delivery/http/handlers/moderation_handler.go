@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/usecases"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
+	"chat-be/package/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type ModerationHandler struct {
+	ModerationUsecase usecases.ModerationUsecase
+}
+
+func NewModerationHandler(moderationUsecase usecases.ModerationUsecase) *ModerationHandler {
+	return &ModerationHandler{ModerationUsecase: moderationUsecase}
+}
+
+func (h *ModerationHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.BlockUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	err := h.ModerationUsecase.BlockUser(user.UserID, request.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Block user error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "User blocked successfully", nil)
+}
+
+func (h *ModerationHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	err := h.ModerationUsecase.UnblockUser(user.UserID, mux.Vars(r)["userId"])
+	if err != nil {
+		logging.LogError(ctx, "Unblock user error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "User unblocked successfully", nil)
+}
+
+func (h *ModerationHandler) GetBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	blocked, err := h.ModerationUsecase.GetBlockedUsers(user.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Get blocked users error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to fetch blocked users", nil)
+		return
+	}
+
+	if blocked == nil {
+		blocked = []models.BlockedUserResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Blocked users fetched successfully", blocked)
+}
+
+func (h *ModerationHandler) ReportUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.CreateReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	report, err := h.ModerationUsecase.ReportUser(user.UserID, request)
+	if err != nil {
+		logging.LogError(ctx, "Report user error: %v", err)
+		status := http.StatusBadRequest
+		if errors.Is(err, usecases.ErrRoomNotFound) {
+			status = http.StatusNotFound
+		}
+		middleware.WriteResponse(w, status, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "Report submitted successfully", report)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/usecases"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
+	"chat-be/package/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type PushHandler struct {
+	PushUsecase usecases.PushUsecase
+}
+
+func NewPushHandler(pushUsecase usecases.PushUsecase) *PushHandler {
+	return &PushHandler{PushUsecase: pushUsecase}
+}
+
+func (h *PushHandler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.RegisterDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	device, err := h.PushUsecase.RegisterDevice(user.UserID, user.SessionID, request)
+	if err != nil {
+		logging.LogError(ctx, "Register device error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to register device", nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "Device registered successfully", device)
+}
+
+func (h *PushHandler) GetDevices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	devices, err := h.PushUsecase.GetDevices(user.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Get devices error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to fetch devices", nil)
+		return
+	}
+
+	if devices == nil {
+		devices = []models.DeviceResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Devices fetched successfully", devices)
+}
+
+func (h *PushHandler) RemoveDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	err := h.PushUsecase.RemoveDevice(user.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		logging.LogError(ctx, "Remove device error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Device removed successfully", nil)
+}
+
+func (h *PushHandler) GetPushSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	settings, err := h.PushUsecase.GetPushSettings(user.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Get push settings error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Push settings fetched successfully", settings)
+}
+
+func (h *PushHandler) UpdatePushSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.PushSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	settings, err := h.PushUsecase.UpdatePushSettings(user.UserID, request)
+	if err != nil {
+		logging.LogError(ctx, "Update push settings error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to update push settings", nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Push settings updated successfully", settings)
+}
+
+func (h *PushHandler) MuteRoom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.MuteRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	mute, err := h.PushUsecase.MuteRoom(user.UserID, mux.Vars(r)["id"], request)
+	if err != nil {
+		logging.LogError(ctx, "Mute room error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Room notification settings updated", mute)
+}
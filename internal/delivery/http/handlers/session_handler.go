@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/usecases"
+	"chat-be/package/logging"
+	"chat-be/package/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+type SessionHandler struct {
+	SessionUsecase usecases.SessionUsecase
+}
+
+func NewSessionHandler(sessionUsecase usecases.SessionUsecase) *SessionHandler {
+	return &SessionHandler{SessionUsecase: sessionUsecase}
+}
+
+func (h *SessionHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	sessions, err := h.SessionUsecase.GetSessions(user.UserID, user.SessionID)
+	if err != nil {
+		logging.LogError(ctx, "Get sessions error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to fetch sessions", nil)
+		return
+	}
+
+	if sessions == nil {
+		sessions = []models.SessionResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Sessions fetched successfully", sessions)
+}
+
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	sessionID := mux.Vars(r)["id"]
+	err := h.SessionUsecase.RevokeSession(user.UserID, sessionID)
+	if err != nil {
+		logging.LogError(ctx, "Revoke session error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Session revoked successfully", nil)
+}
+
+func (h *SessionHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	err := h.SessionUsecase.RevokeOtherSessions(user.UserID, user.SessionID)
+	if err != nil {
+		logging.LogError(ctx, "Revoke other sessions error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to revoke sessions", nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Signed out of all other devices", nil)
+}
@@ -2,13 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
+	"chat-be/internal/delivery/http/models"
 	"chat-be/internal/domain/entities"
 	"chat-be/internal/usecases"
 	"chat-be/package/helper"
 	"chat-be/package/logging"
 	"chat-be/package/middleware"
+	"chat-be/package/validators"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -50,8 +54,9 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	type LoginRequest struct {
-		Email    string `json:"email" validate:"required,email"`
-		Password string `json:"password" validate:"required,min=8"`
+		Email      string `json:"email" validate:"required,email"`
+		Password   string `json:"password" validate:"required,min=8"`
+		DeviceName string `json:"device_name" validate:"omitempty,max=100"`
 	}
 
 	var req LoginRequest
@@ -66,14 +71,18 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.UserUsecase.Login(req.Email, req.Password)
+	response, err := h.UserUsecase.Login(req.Email, req.Password, clientInfo(r, req.DeviceName))
 	if err != nil {
 		logging.LogError(ctx, "Login error: %v", err)
+		if errors.Is(err, usecases.ErrLoginLocked) {
+			middleware.WriteResponse(w, http.StatusTooManyRequests, err.Error(), nil)
+			return
+		}
 		middleware.WriteResponse(w, http.StatusUnauthorized, err.Error(), nil)
 		return
 	}
 
-	middleware.WriteResponse(w, http.StatusOK, "", map[string]string{"token": token})
+	middleware.WriteResponse(w, http.StatusOK, "", response)
 }
 
 func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
@@ -90,8 +99,18 @@ func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1 // Default to page 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 50 {
+		limit = 10 // Default value
+	}
+
 	// Search for users using the usecase
-	users, err := h.UserUsecase.SearchUsers(query, user.UserID)
+	users, total, err := h.UserUsecase.SearchUsers(query, user.UserID, page, limit)
 	if err != nil {
 		logging.LogError(ctx, "Search error: %v", err)
 		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to search users", nil)
@@ -102,12 +121,388 @@ func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 		users = []entities.UserResponse{} // Replace User with the actual type if necessary
 	}
 
+	response := map[string]interface{}{
+		"users": users,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	}
+
 	// You can also handle the case where users are empty, if required
 	if len(users) == 0 {
 		// Optionally, return a message indicating no users were found
-		middleware.WriteResponse(w, http.StatusOK, "No users found", users)
+		middleware.WriteResponse(w, http.StatusOK, "No users found", response)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Users fetched successfully", response)
+}
+
+func (h *UserHandler) GetPrivacySettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	settings, err := h.UserUsecase.GetPrivacySettings(user.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Get privacy settings error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Privacy settings fetched successfully", settings)
+}
+
+func (h *UserHandler) UpdatePrivacySettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.PrivacySettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	settings, err := h.UserUsecase.UpdatePrivacySettings(user.UserID, request)
+	if err != nil {
+		logging.LogError(ctx, "Update privacy settings error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to update privacy settings", nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Privacy settings updated successfully", settings)
+}
+
+func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	profile, err := h.UserUsecase.GetProfile(user.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Get profile error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Profile fetched successfully", profile)
+}
+
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	validators.RegisterCustomValidators(validate)
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
 		return
 	}
 
-	middleware.WriteResponse(w, http.StatusOK, "Users fetched successfully", users)
+	profile, err := h.UserUsecase.UpdateProfile(user.UserID, request)
+	if err != nil {
+		logging.LogError(ctx, "Update profile error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Profile updated successfully", profile)
+}
+
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	err := h.UserUsecase.ChangePassword(user.UserID, user.SessionID, request.OldPassword, request.NewPassword)
+	if err != nil {
+		logging.LogError(ctx, "Change password error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Password changed successfully", nil)
+}
+
+func (h *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var request models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	err := h.UserUsecase.ForgotPassword(request.Email)
+	if err != nil {
+		logging.LogError(ctx, "Forgot password error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to process password reset request", nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "If the email is registered, a password reset link has been sent", nil)
+}
+
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var request models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	err := h.UserUsecase.ResetPassword(request.Token, request.NewPassword)
+	if err != nil {
+		logging.LogError(ctx, "Reset password error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Password reset successfully", nil)
+}
+
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Query parameter 'token' is required", nil)
+		return
+	}
+
+	err := h.UserUsecase.VerifyEmail(token)
+	if err != nil {
+		logging.LogError(ctx, "Verify email error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Email verified successfully", nil)
+}
+
+func (h *UserHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var request models.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	err := h.UserUsecase.ResendVerification(request.Email)
+	if err != nil {
+		logging.LogError(ctx, "Resend verification error: %v", err)
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to send verification email", nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "If the email is registered and unverified, a verification link has been sent", nil)
+}
+
+func (h *UserHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var request models.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	response, err := h.UserUsecase.LoginTwoFactor(request.InterimToken, request.Code, clientInfo(r, request.DeviceName))
+	if err != nil {
+		logging.LogError(ctx, "Two-factor login error: %v", err)
+		if errors.Is(err, usecases.ErrLoginLocked) {
+			middleware.WriteResponse(w, http.StatusTooManyRequests, err.Error(), nil)
+			return
+		}
+		middleware.WriteResponse(w, http.StatusUnauthorized, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "", response)
+}
+
+func (h *UserHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	enrollment, err := h.UserUsecase.EnrollTwoFactor(user.UserID)
+	if err != nil {
+		logging.LogError(ctx, "Enroll two-factor error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Scan the provisioning URI with your authenticator app and confirm with a code", enrollment)
+}
+
+func (h *UserHandler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.TwoFactorConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	recoveryCodes, err := h.UserUsecase.ConfirmTwoFactor(user.UserID, request.Code)
+	if err != nil {
+		logging.LogError(ctx, "Confirm two-factor error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Two-factor authentication enabled, store the recovery codes somewhere safe", models.RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+func (h *UserHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.TwoFactorDisableRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	err := h.UserUsecase.DisableTwoFactor(user.UserID, request.Password, request.Code)
+	if err != nil {
+		logging.LogError(ctx, "Disable two-factor error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+func (h *UserHandler) StartSSOLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	response, err := h.UserUsecase.StartSSOLogin(ctx)
+	if err != nil {
+		logging.LogError(ctx, "Start SSO login error: %v", err)
+		if errors.Is(err, usecases.ErrSSONotConfigured) {
+			middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		middleware.WriteResponse(w, http.StatusInternalServerError, "Failed to start single sign-on", nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "", response)
+}
+
+func (h *UserHandler) CompleteSSOLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var request models.SSOCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	response, err := h.UserUsecase.CompleteSSOLogin(ctx, request.Code, request.State, clientInfo(r, request.DeviceName))
+	if err != nil {
+		logging.LogError(ctx, "Complete SSO login error: %v", err)
+		if errors.Is(err, usecases.ErrSSONotConfigured) {
+			middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		middleware.WriteResponse(w, http.StatusUnauthorized, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "", response)
+}
+
+func clientInfo(r *http.Request, deviceName string) models.ClientInfo {
+	return models.ClientInfo{
+		DeviceName: deviceName,
+		UserAgent:  r.UserAgent(),
+		IP:         middleware.GetClientIP(r),
+	}
 }
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/usecases"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
+	"chat-be/package/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type WebhookHandler struct {
+	WebhookUsecase usecases.WebhookUsecase
+}
+
+func NewWebhookHandler(webhookUsecase usecases.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{WebhookUsecase: webhookUsecase}
+}
+
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	var request models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		middleware.WriteResponse(w, http.StatusBadRequest, helper.GetMessageValidator(validate, err), nil)
+		return
+	}
+
+	webhook, err := h.WebhookUsecase.CreateWebhook(user.UserID, mux.Vars(r)["id"], request)
+	if err != nil {
+		logging.LogError(ctx, "Create webhook error: %v", err)
+		middleware.WriteResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusCreated, "Webhook created, store the secret now as it will not be shown again", webhook)
+}
+
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	webhooks, err := h.WebhookUsecase.GetWebhooks(user.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		logging.LogError(ctx, "Get webhooks error: %v", err)
+		middleware.WriteResponse(w, http.StatusForbidden, err.Error(), nil)
+		return
+	}
+
+	if webhooks == nil {
+		webhooks = []models.WebhookResponse{}
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Webhooks fetched successfully", webhooks)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err := h.WebhookUsecase.DeleteWebhook(user.UserID, vars["id"], vars["webhookId"])
+	if err != nil {
+		logging.LogError(ctx, "Delete webhook error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Webhook deleted successfully", nil)
+}
+
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		middleware.WriteResponse(w, http.StatusUnauthorized, "User token not valid", nil)
+		return
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 20 // Default value
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1 // Default to page 1
+	}
+
+	vars := mux.Vars(r)
+	deliveries, total, err := h.WebhookUsecase.GetDeliveries(user.UserID, vars["id"], vars["webhookId"], page, limit)
+	if err != nil {
+		logging.LogError(ctx, "Get webhook deliveries error: %v", err)
+		middleware.WriteResponse(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []models.WebhookDeliveryResponse{}
+	}
+
+	response := map[string]interface{}{
+		"deliveries": deliveries,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+	}
+
+	middleware.WriteResponse(w, http.StatusOK, "Webhook deliveries fetched successfully", response)
+}
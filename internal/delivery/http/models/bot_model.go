@@ -0,0 +1,27 @@
+package models
+
+type CreateBotRequest struct {
+	Username    string `json:"username" validate:"required,min=3,max=50"`
+	DisplayName string `json:"display_name" validate:"omitempty,max=100"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=messages:send messages:read"`
+	RoomIDs       []string `json:"room_ids" validate:"omitempty,dive,uuid"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type APIKeyResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	RoomIDs    []string `json:"room_ids"`
+	LastUsedAt string   `json:"last_used_at"`
+	ExpiresAt  string   `json:"expires_at"`
+	Revoked    bool     `json:"revoked"`
+	CreatedAt  string   `json:"created_at"`
+	// Key is only returned once, when the key is created
+	Key string `json:"key,omitempty"`
+}
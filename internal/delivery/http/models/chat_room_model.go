@@ -6,9 +6,25 @@ type CreateChatRoomRequest struct {
 	RoomName string   `json:"room_name"`
 }
 
+type CreateChannelRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=255"`
+	Description string `json:"description" validate:"omitempty,max=255"`
+	Avatar      string `json:"avatar" validate:"omitempty,base64image,imageformat"`
+	IsPrivate   bool   `json:"is_private"`
+}
+
 type GetChatRoomResponse struct {
 	ID              string         `json:"id"`
 	Name            string         `json:"name"`
+	Description     string         `json:"description"`
+	Avatar          string         `json:"avatar"`
+	IsGroup         bool           `json:"is_group"`
+	IsPrivate       bool           `json:"is_private"`
+	IsChannel       bool           `json:"is_channel"`
+	Role            string         `json:"role"`
+	MutedUntil      string         `json:"muted_until"`
+	Pinned          bool           `json:"pinned"`
+	Archived        bool           `json:"archived"`
 	LastMessage     string         `json:"last_message"`
 	LastMessageTime string         `json:"last_message_time"`
 	Participants    []Participants `json:"participants"`
@@ -17,4 +33,79 @@ type GetChatRoomResponse struct {
 type Participants struct {
 	UserID     string `json:"user_id"`
 	SocketPath string `json:"socket_path"`
+	Online     bool   `json:"online"`
+	LastSeenAt string `json:"last_seen_at"`
+	Role       string `json:"role,omitempty"`
+}
+
+type RoomListFilter struct {
+	Archived    bool
+	Name        string `validate:"omitempty,max=100"`
+	Participant string `validate:"omitempty,max=100"`
+	Type        string `validate:"omitempty,oneof=direct group channel"`
+	UnreadOnly  bool
+}
+
+type PinRoomRequest struct {
+	Pinned bool `json:"pinned"`
+}
+
+type ArchiveRoomRequest struct {
+	Archived bool `json:"archived"`
+}
+
+type UpdateRoomRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=255"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
+	Avatar      *string `json:"avatar" validate:"omitempty,base64image,imageformat"`
+	IsPrivate   *bool   `json:"is_private"`
+}
+
+type RoomUpdatedData struct {
+	RoomID      string `json:"room_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Avatar      string `json:"avatar"`
+	IsPrivate   bool   `json:"is_private"`
+	UpdatedBy   string `json:"updated_by"`
+}
+
+type AddMembersRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,dive,uuid"`
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin member"`
+}
+
+type CreateInviteRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" validate:"omitempty,min=1,max=8760"`
+	MaxUses        int `json:"max_uses" validate:"omitempty,min=1,max=10000"`
+}
+
+type InviteResponse struct {
+	ID        string `json:"id"`
+	RoomID    string `json:"room_id"`
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+	MaxUses   int    `json:"max_uses"`
+	Uses      int    `json:"uses"`
+	Revoked   bool   `json:"revoked"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CreateJoinRequestRequest struct {
+	Message string `json:"message" validate:"omitempty,max=255"`
+}
+
+type JoinRequestResponse struct {
+	ID        string `json:"id"`
+	RoomID    string `json:"room_id"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Message   string `json:"message"`
+	Status    string `json:"status"`
+	DecidedBy string `json:"decided_by"`
+	DecidedAt string `json:"decided_at"`
+	CreatedAt string `json:"created_at"`
 }
@@ -0,0 +1,26 @@
+package models
+
+type SendFriendRequestRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+}
+
+type ContactResponse struct {
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar"`
+	Online      bool   `json:"online"`
+	LastSeenAt  string `json:"last_seen_at"`
+	Since       string `json:"since"`
+}
+
+type FriendRequestResponse struct {
+	ID          string `json:"id"`
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar"`
+	Direction   string `json:"direction"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+}
@@ -8,3 +8,31 @@ type Message struct {
 	Time   string `json:"time"`
 	Status int    `json:"status"`
 }
+
+type SendMessageRequest struct {
+	RoomID  string `json:"room_id" validate:"required,uuid"`
+	Content string `json:"content" validate:"required,max=4000"`
+}
+
+type MessageSearchResponse struct {
+	ID       string `json:"id"`
+	RoomID   string `json:"room_id"`
+	SenderID string `json:"sender_id"`
+	Text     string `json:"text"`
+	Snippet  string `json:"snippet"`
+	Time     string `json:"time"`
+}
+
+type PinMessageRequest struct {
+	MessageID string `json:"message_id" validate:"required,uuid"`
+}
+
+type PinnedMessageResponse struct {
+	RoomID    string `json:"room_id"`
+	MessageID string `json:"message_id"`
+	SenderID  string `json:"sender_id"`
+	Text      string `json:"text"`
+	Time      string `json:"time"`
+	PinnedBy  string `json:"pinned_by"`
+	PinnedAt  string `json:"pinned_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+type BlockUserRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+}
+
+type BlockedUserResponse struct {
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar"`
+	BlockedAt   string `json:"blocked_at"`
+}
+
+type CreateReportRequest struct {
+	UserID     string   `json:"user_id" validate:"required,uuid"`
+	RoomID     string   `json:"room_id" validate:"omitempty,uuid"`
+	Reason     string   `json:"reason" validate:"required,oneof=spam harassment inappropriate other"`
+	Details    string   `json:"details" validate:"omitempty,max=1000"`
+	MessageIDs []string `json:"message_ids" validate:"omitempty,max=20,dive,uuid"`
+}
+
+type ReportResponse struct {
+	ID             string `json:"id"`
+	ReportedUserID string `json:"reported_user_id"`
+	RoomID         string `json:"room_id"`
+	Reason         string `json:"reason"`
+	Status         string `json:"status"`
+	EvidenceCount  int    `json:"evidence_count"`
+	CreatedAt      string `json:"created_at"`
+}
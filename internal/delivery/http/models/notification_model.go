@@ -0,0 +1,14 @@
+package models
+
+import "encoding/json"
+
+// Notification is an event kept for a user who was offline when it happened,
+// it is replayed to the user's connection as an event of the same type on reconnect
+type Notification struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	Type      string          `json:"type"`
+	RoomID    string          `json:"room_id"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt string          `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// PresenceEvent is sent by the WebSocket gateway when a connection opens, closes or sends a heartbeat
+type PresenceEvent struct {
+	UserID       string `json:"user_id"`
+	ConnectionID string `json:"connection_id"`
+	Status       string `json:"status"`
+}
+
+type PresenceResponse struct {
+	UserID     string `json:"user_id"`
+	Online     bool   `json:"online"`
+	LastSeenAt string `json:"last_seen_at"`
+}
+
+// TypingEvent is sent by the WebSocket gateway when a user starts or stops typing in a room
+type TypingEvent struct {
+	UserID string `json:"user_id"`
+	RoomID string `json:"room_id"`
+	Typing bool   `json:"typing"`
+}
+
+type TypingResponse struct {
+	UserID string `json:"user_id"`
+	RoomID string `json:"room_id"`
+	Typing bool   `json:"typing"`
+	// Clients hide the indicator after this many seconds unless it is renewed
+	ExpiresIn int `json:"expires_in"`
+}
@@ -0,0 +1,32 @@
+package models
+
+type RegisterDeviceRequest struct {
+	Token    string `json:"token" validate:"required,max=512"`
+	Platform string `json:"platform" validate:"required,oneof=android ios web"`
+}
+
+type DeviceResponse struct {
+	ID        string `json:"id"`
+	Platform  string `json:"platform"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type PushSettingsRequest struct {
+	Preview string `json:"preview" validate:"required,oneof=full sender none"`
+}
+
+type PushSettingsResponse struct {
+	Preview string `json:"preview"`
+}
+
+type MuteRoomRequest struct {
+	Muted bool `json:"muted"`
+	// Zero mutes until the room is unmuted
+	DurationMinutes int `json:"duration_minutes" validate:"omitempty,min=1,max=525600"`
+}
+
+type MuteRoomResponse struct {
+	RoomID     string `json:"room_id"`
+	MutedUntil string `json:"muted_until"`
+}
@@ -0,0 +1,94 @@
+package models
+
+type UpdateProfileRequest struct {
+	Username    *string `json:"username" validate:"omitempty,min=3,max=50"`
+	DisplayName *string `json:"display_name" validate:"omitempty,max=100"`
+	Bio         *string `json:"bio" validate:"omitempty,max=255"`
+	Avatar      *string `json:"avatar" validate:"omitempty,base64image,imageformat"`
+}
+
+type PrivacySettingsRequest struct {
+	Discoverable *bool `json:"discoverable"`
+	ContactsOnly *bool `json:"contacts_only"`
+}
+
+type PrivacySettingsResponse struct {
+	Discoverable bool `json:"discoverable"`
+	ContactsOnly bool `json:"contacts_only"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type LoginResponse struct {
+	Token             string `json:"token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	InterimToken      string `json:"interim_token,omitempty"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	InterimToken string `json:"interim_token" validate:"required"`
+	Code         string `json:"code" validate:"required"`
+	DeviceName   string `json:"device_name" validate:"omitempty,max=100"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// ClientInfo describes the device a login request comes from
+type ClientInfo struct {
+	DeviceName string
+	UserAgent  string
+	IP         string
+}
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	DeviceName string `json:"device_name"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	LastSeenAt string `json:"last_seen_at"`
+	CreatedAt  string `json:"created_at"`
+	Current    bool   `json:"current"`
+}
+
+type SSOLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+type SSOCallbackRequest struct {
+	Code       string `json:"code" validate:"required"`
+	State      string `json:"state" validate:"required"`
+	DeviceName string `json:"device_name" validate:"omitempty,max=100"`
+}
@@ -0,0 +1,51 @@
+package models
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=message.new membership.changed"`
+}
+
+type WebhookResponse struct {
+	ID        string   `json:"id"`
+	RoomID    string   `json:"room_id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	CreatedAt string   `json:"created_at"`
+	// Secret is only returned once, when the webhook is created
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string `json:"id"`
+	Event          string `json:"event"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	LastStatusCode int    `json:"last_status_code"`
+	LastError      string `json:"last_error"`
+	NextAttemptAt  string `json:"next_attempt_at"`
+	DeliveredAt    string `json:"delivered_at"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// WebhookPayload is the JSON body posted to webhook receivers
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	RoomID    string      `json:"room_id"`
+	CreatedAt string      `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type WebhookMessageData struct {
+	ID        string `json:"id"`
+	SenderID  string `json:"sender_id"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+}
+
+type WebhookMembershipData struct {
+	Action  string   `json:"action"`
+	ActorID string   `json:"actor_id"`
+	UserIDs []string `json:"user_ids"`
+	Role    string   `json:"role,omitempty"`
+}
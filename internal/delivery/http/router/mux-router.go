@@ -31,6 +31,10 @@ func (*muxRouter) DELETE(uri string, f func(w http.ResponseWriter, r *http.Reque
 	muxDispatcher.HandleFunc(uri, f).Methods("DELETE")
 }
 
+func (*muxRouter) PATCH(uri string, f func(w http.ResponseWriter, r *http.Request)) {
+	muxDispatcher.HandleFunc(uri, f).Methods("PATCH")
+}
+
 func (*muxRouter) GETWithMiddleware(uri string, f func(w http.ResponseWriter, r *http.Request), middlewares ...mux.MiddlewareFunc) {
 	subRouter := muxDispatcher.PathPrefix(uri).Subrouter()
 	subRouter.Use(middlewares...)
@@ -55,6 +59,12 @@ func (*muxRouter) DELETEWithMiddleware(uri string, f func(w http.ResponseWriter,
 	subRouter.HandleFunc("", f).Methods("DELETE")
 }
 
+func (*muxRouter) PATCHWithMiddleware(uri string, f func(w http.ResponseWriter, r *http.Request), middlewares ...mux.MiddlewareFunc) {
+	subRouter := muxDispatcher.PathPrefix(uri).Subrouter()
+	subRouter.Use(middlewares...)
+	subRouter.HandleFunc("", f).Methods("PATCH")
+}
+
 func (*muxRouter) OPTIONS(uri string) {
 	muxDispatcher.HandleFunc(uri, func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
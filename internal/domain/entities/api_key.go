@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// Scopes that can be granted to a bot API key
+const (
+	ScopeMessagesSend = "messages:send"
+	ScopeMessagesRead = "messages:read"
+)
+
+type APIKey struct {
+	ID         string     `gorm:"type:uuid;primaryKey"`
+	BotID      string     `gorm:"type:uuid;not null;index"`
+	Bot        User       `gorm:"foreignKey:BotID;references:ID"`
+	Name       string     `gorm:"type:varchar(100);not null"`
+	Prefix     string     `gorm:"type:varchar(16);not null"` // Shown to users to tell keys apart
+	KeyHash    string     `gorm:"type:varchar(64);unique;not null"`
+	Scopes     []string   `gorm:"serializer:json;not null"`
+	RoomIDs    []string   `gorm:"serializer:json"` // Empty means every room the bot participates in
+	LastUsedAt *time.Time `gorm:"null"`
+	ExpiresAt  *time.Time `gorm:"null"`
+	RevokedAt  *time.Time `gorm:"null"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
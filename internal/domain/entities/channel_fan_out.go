@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+const (
+	ChannelFanOutPending = "pending"
+	ChannelFanOutDone    = "done"
+	ChannelFanOutFailed  = "failed"
+)
+
+// ChannelFanOut is the outbox entry of a channel post, the worker writes the subscriber receipts
+// in batches ordered by user ID and records the last one so a restart carries on from there
+type ChannelFanOut struct {
+	ID            string    `gorm:"type:uuid;primaryKey"`
+	MessageID     string    `gorm:"type:uuid;not null;uniqueIndex"`
+	ChatRoomID    string    `gorm:"type:uuid;not null"`
+	SenderID      string    `gorm:"type:uuid;not null"`
+	LastUserID    string    `gorm:"type:varchar(36);not null;default:''"` // Empty until the first batch is written
+	Status        string    `gorm:"type:varchar(20);not null;index"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
@@ -9,7 +9,12 @@ import (
 type ChatRoom struct {
 	ID            string                `gorm:"type:uuid;primaryKey" json:"id"`
 	Name          string                `gorm:"type:varchar(255)" json:"name"`
+	Description   string                `gorm:"type:varchar(255)" json:"description"`
+	Avatar        string                `gorm:"type:text" json:"avatar"`
 	IsGroup       bool                  `gorm:"not null;default:false" json:"is_group"`
+	IsPrivate     bool                  `gorm:"not null;default:false" json:"is_private"` // Joining needs an admin's approval
+	IsChannel     bool                  `gorm:"not null;default:false" json:"is_channel"` // Broadcast group, only admins post
+	CreatedBy     *string               `gorm:"type:uuid;null" json:"created_by"`
 	LastMessageID *string               `gorm:"type:uuid;null" json:"last_message_id"`
 	Message       Message               `gorm:"foreignKey:LastMessageID;references:ID"`
 	Participants  []ChatRoomParticipant `gorm:"foreignKey:ChatRoomID" json:"participants"`
@@ -19,11 +24,27 @@ type ChatRoom struct {
 }
 
 type ChatRoomParticipant struct {
-	ID         string    `gorm:"type:uuid;primaryKey" json:"id"`
-	ChatRoomID string    `gorm:"type:uuid;not null" json:"chat_room_id"`
-	UserID     string    `gorm:"type:uuid;not null" json:"user_id"`
-	User       User      `gorm:"foreignKey:UserID;references:ID"`
-	JoinedAt   time.Time `gorm:"not null" json:"joined_at"`
-	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID         string     `gorm:"type:uuid;primaryKey" json:"id"`
+	ChatRoomID string     `gorm:"type:uuid;not null;index;uniqueIndex:idx_participants_user_room,priority:2" json:"chat_room_id"`
+	UserID     string     `gorm:"type:uuid;not null;uniqueIndex:idx_participants_user_room,priority:1" json:"user_id"`
+	User       User       `gorm:"foreignKey:UserID;references:ID"`
+	Role       string     `gorm:"type:varchar(10);not null;default:'member'" json:"role"`
+	JoinedAt   time.Time  `gorm:"not null" json:"joined_at"`
+	MutedUntil *time.Time `gorm:"null" json:"muted_until"` // Push notifications for the room are skipped until then
+	PinnedAt   *time.Time `gorm:"null" json:"pinned_at"`   // Pinned rooms are listed first, latest pin on top
+	ArchivedAt *time.Time `gorm:"null" json:"archived_at"` // Archived rooms are only listed when asked for
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Roles of a group participant, direct rooms only have members
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// CanManage reports whether the participant may change the room's membership
+func (p ChatRoomParticipant) CanManage() bool {
+	return p.Role == RoleOwner || p.Role == RoleAdmin
 }
@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+const (
+	ContactPending  = "pending"
+	ContactAccepted = "accepted"
+	ContactDeclined = "declined"
+)
+
+// Contact is the friendship between two users, there is one row per pair whoever asked first.
+// RequesterID is the user who sent the latest friend request.
+type Contact struct {
+	ID          string     `gorm:"type:uuid;primaryKey"`
+	RequesterID string     `gorm:"type:uuid;not null;index"`
+	Requester   User       `gorm:"foreignKey:RequesterID;references:ID"`
+	AddresseeID string     `gorm:"type:uuid;not null;index"`
+	Addressee   User       `gorm:"foreignKey:AddresseeID;references:ID"`
+	Status      string     `gorm:"type:varchar(10);not null"`
+	RespondedAt *time.Time `gorm:"null"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime"`
+}
+
+// Other returns the user of the pair that is not userID
+func (c Contact) Other(userID string) User {
+	if c.RequesterID == userID {
+		return c.Addressee
+	}
+	return c.Requester
+}
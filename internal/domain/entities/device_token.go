@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+	PlatformWeb     = "web"
+)
+
+// How much of a message a push notification reveals on the lock screen
+const (
+	PushPreviewFull   = "full"   // Sender and message text
+	PushPreviewSender = "sender" // Sender only
+	PushPreviewNone   = "none"   // A generic "New message"
+)
+
+type DeviceToken struct {
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    string    `gorm:"type:uuid;not null;index" json:"-"`
+	SessionID *string   `gorm:"type:uuid;null;index" json:"-"` // Session that registered the token, revoking it removes the token
+	Token     string    `gorm:"type:varchar(512);not null;uniqueIndex" json:"-"`
+	Platform  string    `gorm:"type:varchar(10);not null" json:"platform"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
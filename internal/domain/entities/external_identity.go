@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// ExternalIdentity links a user to an account at an external identity provider
+type ExternalIdentity struct {
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	UserID    string    `gorm:"type:uuid;not null;index"`
+	Provider  string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_external_identity_subject"` // Issuer URL of the provider
+	Subject   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_external_identity_subject"`
+	Email     string    `gorm:"type:varchar(255)"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// SSOLoginState keeps the PKCE verifier and nonce between starting a login and its callback
+type SSOLoginState struct {
+	State        string    `gorm:"type:varchar(64);primaryKey"` // SHA-256 of the state parameter
+	Nonce        string    `gorm:"type:varchar(128);not null"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestRejected = "rejected"
+)
+
+type JoinRequest struct {
+	ID         string     `gorm:"type:uuid;primaryKey"`
+	ChatRoomID string     `gorm:"type:uuid;not null;uniqueIndex:idx_join_requests_pending,where:status = 'pending'"`
+	UserID     string     `gorm:"type:uuid;not null;uniqueIndex:idx_join_requests_pending,where:status = 'pending'"`
+	User       User       `gorm:"foreignKey:UserID;references:ID"`
+	Message    string     `gorm:"type:varchar(255)"`
+	Status     string     `gorm:"type:varchar(10);not null;index"`
+	DecidedBy  *string    `gorm:"type:uuid;null"`
+	DecidedAt  *time.Time `gorm:"null"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
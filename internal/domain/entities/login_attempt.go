@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+type LoginAttempt struct {
+	Key          string     `gorm:"type:varchar(320);primaryKey"` // "email:<address>" or "ip:<address>"
+	FailedCount  int        `gorm:"not null;default:0"`
+	LockedUntil  *time.Time `gorm:"null"`
+	LastFailedAt time.Time  `gorm:"not null"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+}
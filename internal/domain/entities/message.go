@@ -8,26 +8,33 @@ import (
 
 type Message struct {
 	ID            string          `gorm:"type:uuid;primaryKey" json:"id"`
-	ChatRoomID    string          `gorm:"type:uuid;not null" json:"chat_room_id"` // Referensi ke ChatRoom
-	SenderID      string          `gorm:"type:uuid;not null" json:"sender_id"`    // ID pengirim pesan
+	ChatRoomID    string          `gorm:"type:uuid;not null;index:idx_messages_room_created,priority:1" json:"chat_room_id"` // Referensi ke ChatRoom
+	SenderID      string          `gorm:"type:uuid;not null" json:"sender_id"`                                               // ID pengirim pesan
 	Content       string          `gorm:"not null" json:"content"`
+	Type          string          `gorm:"type:varchar(10);not null;default:'text'" json:"type"`
 	Status        int             `gorm:"not null" json:"status"`
 	MessageStatus []MessageStatus `gorm:"foreignKey:MessageID;references:ID" json:"message_status"`
-	CreatedAt     time.Time       `gorm:"autoCreateTime"`
+	CreatedAt     time.Time       `gorm:"autoCreateTime;index:idx_messages_room_created,priority:2"`
 	UpdatedAt     time.Time       `gorm:"autoUpdateTime"`
 	DeletedAt     gorm.DeletedAt  `gorm:"index"`
 }
 
 type MessageStatus struct {
 	ID         string         `gorm:"type:uuid;primaryKey" json:"id"`
-	MessageID  string         `gorm:"type:uuid;not null" json:"message_id"`
-	ReceiverID string         `gorm:"type:uuid;not null" json:"receiver_id"`
-	Status     int            `gorm:"not null" json:"status"`
+	MessageID  string         `gorm:"type:uuid;not null;index" json:"message_id"`
+	ReceiverID string         `gorm:"type:uuid;not null;index:idx_message_statuses_receiver_status,priority:1" json:"receiver_id"`
+	Status     int            `gorm:"not null;index:idx_message_statuses_receiver_status,priority:2" json:"status"`
 	CreatedAt  time.Time      `gorm:"autoCreateTime"`
 	UpdatedAt  time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt  gorm.DeletedAt `gorm:"index"`
 }
 
+// Message types, system messages record room changes such as members joining or leaving
+const (
+	MessageTypeText   = "text"
+	MessageTypeSystem = "system"
+)
+
 const (
 	StatusSend      = 1
 	StatusDelivered = 2
@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+type PasswordResetToken struct {
+	ID        string     `gorm:"type:uuid;primaryKey"`
+	UserID    string     `gorm:"type:uuid;not null;index"`
+	TokenHash string     `gorm:"type:varchar(64);unique;not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time `gorm:"null"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+type PinnedMessage struct {
+	ID         string    `gorm:"type:uuid;primaryKey"`
+	ChatRoomID string    `gorm:"type:uuid;not null;uniqueIndex:idx_pinned_messages_room_message"`
+	MessageID  string    `gorm:"type:uuid;not null;uniqueIndex:idx_pinned_messages_room_message"`
+	Message    Message   `gorm:"foreignKey:MessageID;references:ID"`
+	PinnedBy   string    `gorm:"type:uuid;not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+type RecoveryCode struct {
+	ID        string     `gorm:"type:uuid;primaryKey"`
+	UserID    string     `gorm:"type:uuid;not null;index"`
+	CodeHash  string     `gorm:"not null"`
+	UsedAt    *time.Time `gorm:"null"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,43 @@
+package entities
+
+import "time"
+
+// Reasons a user can be reported for
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonInappropriate = "inappropriate"
+	ReportReasonOther         = "other"
+)
+
+// Moderation case states
+const (
+	ReportOpen     = "open"
+	ReportResolved = "resolved"
+)
+
+// Report is a moderation case opened against a user
+type Report struct {
+	ID             string           `gorm:"type:uuid;primaryKey"`
+	ReporterID     string           `gorm:"type:uuid;not null;index"`
+	ReportedUserID string           `gorm:"type:uuid;not null;index"`
+	ChatRoomID     *string          `gorm:"type:uuid;null"`
+	Reason         string           `gorm:"type:varchar(20);not null"`
+	Details        string           `gorm:"type:text"`
+	Status         string           `gorm:"type:varchar(10);not null;default:'open';index"`
+	Evidence       []ReportEvidence `gorm:"foreignKey:ReportID"`
+	CreatedAt      time.Time        `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time        `gorm:"autoUpdateTime"`
+}
+
+// ReportEvidence is a copy of a reported message, kept even if the message is deleted later
+type ReportEvidence struct {
+	ID         string    `gorm:"type:uuid;primaryKey"`
+	ReportID   string    `gorm:"type:uuid;not null;index"`
+	MessageID  string    `gorm:"type:uuid;not null"`
+	ChatRoomID string    `gorm:"type:uuid;not null"`
+	SenderID   string    `gorm:"type:uuid;not null"`
+	Content    string    `gorm:"not null"`
+	SentAt     time.Time `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+type RoomInvite struct {
+	ID         string     `gorm:"type:uuid;primaryKey"`
+	ChatRoomID string     `gorm:"type:uuid;not null;index"`
+	Token      string     `gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedBy  string     `gorm:"type:uuid;not null"`
+	ExpiresAt  *time.Time `gorm:"null"`
+	MaxUses    int        `gorm:"not null;default:0"` // Zero allows unlimited joins
+	Uses       int        `gorm:"not null;default:0"`
+	RevokedAt  *time.Time `gorm:"null"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+type Session struct {
+	ID         string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     string     `gorm:"type:uuid;not null;index" json:"-"`
+	DeviceName string     `gorm:"type:varchar(100)" json:"device_name"`
+	UserAgent  string     `gorm:"type:varchar(512)" json:"user_agent"`
+	IP         string     `gorm:"type:varchar(64)" json:"ip"`
+	LastSeenAt time.Time  `gorm:"not null" json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"null" json:"-"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
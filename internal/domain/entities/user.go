@@ -7,18 +7,41 @@ import (
 )
 
 type User struct {
-	ID         string         `gorm:"type:uuid;primaryKey"`
-	Username   string         `gorm:"unique;not null" json:"username" validate:"required"`
-	Email      string         `gorm:"unique;not null" json:"email" validate:"required,email"`
-	Password   string         `gorm:"not null" json:"password" validate:"required,min=8"`
-	SocketID   string         `gorm:"type:uuid" json:"socket_id"`
-	SocketPath SocketPath     `gorm:"foreignKey:SocketID;references:ID"`
-	CreatedAt  time.Time      `gorm:"autoCreateTime"`
-	DeletedAt  gorm.DeletedAt `gorm:"index"`
+	ID                 string         `gorm:"type:uuid;primaryKey"`
+	Username           string         `gorm:"unique;not null" json:"username" validate:"required"`
+	Email              string         `gorm:"unique;not null" json:"email" validate:"required,email"`
+	Password           string         `gorm:"not null" json:"password" validate:"required,min=8"`
+	DisplayName        string         `gorm:"type:varchar(100)" json:"display_name" validate:"omitempty,max=100"`
+	Bio                string         `gorm:"type:varchar(255)" json:"bio" validate:"omitempty,max=255"`
+	Avatar             string         `gorm:"type:text" json:"avatar" validate:"omitempty,base64"`
+	EmailVerifiedAt    *time.Time     `gorm:"null" json:"-"` // Nil until the verification link is opened
+	VerificationSentAt *time.Time     `gorm:"null" json:"-"`
+	TOTPSecret         string         `gorm:"type:text" json:"-"` // Encrypted, set from enrollment until 2FA is disabled
+	TOTPEnabledAt      *time.Time     `gorm:"null" json:"-"`
+	IsBot              bool           `gorm:"not null;default:false" json:"-"`
+	OwnerID            *string        `gorm:"type:uuid;null;index" json:"-"` // User that created the bot
+	Online             bool           `gorm:"not null;default:false;index" json:"-"`
+	LastSeenAt         *time.Time     `gorm:"null" json:"-"` // Last connect, heartbeat or disconnect from the WebSocket gateway
+	PushPreview        string         `gorm:"type:varchar(10);not null;default:'full'" json:"-"`
+	Discoverable       bool           `gorm:"not null;default:true" json:"-"`  // When false only an exact username or email finds the user
+	ContactsOnly       bool           `gorm:"not null;default:false" json:"-"` // Only accepted contacts may start a direct room
+	SocketID           string         `gorm:"type:uuid" json:"socket_id"`
+	SocketPath         SocketPath     `gorm:"foreignKey:SocketID;references:ID"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt          gorm.DeletedAt `gorm:"index"`
 }
 
 type UserResponse struct {
-	ID       string `json:"user_id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	ID            string `json:"user_id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	DisplayName   string `json:"display_name"`
+	Bio           string `json:"bio"`
+	Avatar        string `json:"avatar"`
+	EmailVerified bool   `json:"email_verified"`
+	TwoFactor     bool   `json:"two_factor_enabled"`
+	IsBot         bool   `json:"is_bot"`
+	Online        bool   `json:"online"`
+	LastSeenAt    string `json:"last_seen_at"`
 }
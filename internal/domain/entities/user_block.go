@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+type UserBlock struct {
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	BlockerID string    `gorm:"type:uuid;not null;uniqueIndex:idx_user_blocks_pair"`
+	BlockedID string    `gorm:"type:uuid;not null;uniqueIndex:idx_user_blocks_pair;index"`
+	Blocked   User      `gorm:"foreignKey:BlockedID;references:ID"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
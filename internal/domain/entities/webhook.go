@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Room events that can be delivered to webhooks
+const (
+	WebhookEventMessageNew        = "message.new"
+	WebhookEventMembershipChanged = "membership.changed"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	ID         string         `gorm:"type:uuid;primaryKey"`
+	ChatRoomID string         `gorm:"type:uuid;not null;index"`
+	URL        string         `gorm:"type:varchar(2048);not null"`
+	Secret     string         `gorm:"type:varchar(128);not null"` // Shared with the receiver to verify signatures
+	Events     []string       `gorm:"serializer:json;not null"`
+	CreatedBy  string         `gorm:"type:uuid;not null"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime"`
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
+
+type WebhookDelivery struct {
+	ID             string     `gorm:"type:uuid;primaryKey"`
+	WebhookID      string     `gorm:"type:uuid;not null;index"`
+	Webhook        Webhook    `gorm:"foreignKey:WebhookID;references:ID"`
+	Event          string     `gorm:"type:varchar(50);not null"`
+	Payload        string     `gorm:"type:text;not null"`
+	Status         string     `gorm:"type:varchar(20);not null;index"`
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `gorm:"not null;index"`
+	LastStatusCode int        `gorm:"not null;default:0"`
+	LastError      string     `gorm:"type:text"`
+	DeliveredAt    *time.Time `gorm:"null"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+}
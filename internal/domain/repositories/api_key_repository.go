@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(apiKey *entities.APIKey) error
+	FindByHash(keyHash string) (*entities.APIKey, error)
+	FindByID(id string) (*entities.APIKey, error)
+	FindByBotID(botID string) ([]entities.APIKey, error)
+	TouchLastUsed(id string, lastUsedAt time.Time) error
+	Revoke(id string) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+func (r *apiKeyRepository) Create(apiKey *entities.APIKey) error {
+	return r.db.Omit("Bot").Create(apiKey).Error
+}
+
+func (r *apiKeyRepository) FindByHash(keyHash string) (*entities.APIKey, error) {
+	var apiKey entities.APIKey
+	err := r.db.Preload("Bot.SocketPath").Where("key_hash = ?", keyHash).First(&apiKey).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) FindByID(id string) (*entities.APIKey, error) {
+	var apiKey entities.APIKey
+	err := r.db.Where("id = ?", id).First(&apiKey).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) FindByBotID(botID string) ([]entities.APIKey, error) {
+	var apiKeys []entities.APIKey
+	err := r.db.Where("bot_id = ?", botID).Order("created_at DESC").Find(&apiKeys).Error
+	if err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id string, lastUsedAt time.Time) error {
+	return r.db.Model(&entities.APIKey{}).Where("id = ?", id).Update("last_used_at", lastUsedAt).Error
+}
+
+func (r *apiKeyRepository) Revoke(id string) error {
+	return r.db.Model(&entities.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
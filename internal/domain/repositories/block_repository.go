@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BlockRepository interface {
+	Create(block *entities.UserBlock) error
+	Delete(blockerID, blockedID string) (bool, error)
+	FindByBlocker(blockerID string) ([]entities.UserBlock, error)
+	IsBlockedEither(userID, otherID string) (bool, error)
+	FindBlockerIDs(blockedID string, blockerIDs []string) ([]string, error)
+}
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockRepository(db *gorm.DB) BlockRepository {
+	return &blockRepository{db}
+}
+
+// Create blocks a user, blocking the same user twice is a no-op
+func (r *blockRepository) Create(block *entities.UserBlock) error {
+	return r.db.Omit("Blocked").
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "blocker_id"}, {Name: "blocked_id"}}, DoNothing: true}).
+		Create(block).Error
+}
+
+func (r *blockRepository) Delete(blockerID, blockedID string) (bool, error) {
+	result := r.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&entities.UserBlock{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *blockRepository) FindByBlocker(blockerID string) ([]entities.UserBlock, error) {
+	var blocks []entities.UserBlock
+	err := r.db.Preload("Blocked").
+		Where("blocker_id = ?", blockerID).
+		Order("created_at DESC").
+		Find(&blocks).Error
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// IsBlockedEither reports whether one of the two users blocked the other
+func (r *blockRepository) IsBlockedEither(userID, otherID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entities.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindBlockerIDs returns which of blockerIDs blocked blockedID
+func (r *blockRepository) FindBlockerIDs(blockedID string, blockerIDs []string) ([]string, error) {
+	var ids []string
+	if len(blockerIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&entities.UserBlock{}).
+		Where("blocked_id = ? AND blocker_id IN ?", blockedID, blockerIDs).
+		Pluck("blocker_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChannelFanOutRepository interface {
+	CreateWithMessage(message *entities.Message, fanOut *entities.ChannelFanOut) error
+	ClaimDue(limit int, lease time.Duration) ([]entities.ChannelFanOut, error)
+	SaveBatch(fanOut *entities.ChannelFanOut, messageStatuses []entities.MessageStatus, lease time.Duration) error
+	Reschedule(fanOut *entities.ChannelFanOut) error
+}
+
+type channelFanOutRepository struct {
+	db *gorm.DB
+}
+
+func NewChannelFanOutRepository(db *gorm.DB) ChannelFanOutRepository {
+	return &channelFanOutRepository{db}
+}
+
+// CreateWithMessage stores a channel post together with its fan-out, so a post is never left without receipts.
+// Saving the same post again is a no-op
+func (r *channelFanOutRepository) CreateWithMessage(message *entities.Message, fanOut *entities.ChannelFanOut) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, DoNothing: true}).Create(message).Error
+		if err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "message_id"}}, DoNothing: true}).Create(fanOut).Error
+	})
+}
+
+// ClaimDue locks pending fan-outs that are due and pushes their next attempt past the lease,
+// so several workers can run without writing the same batch twice
+func (r *channelFanOutRepository) ClaimDue(limit int, lease time.Duration) ([]entities.ChannelFanOut, error) {
+	var fanOuts []entities.ChannelFanOut
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entities.ChannelFanOutPending, time.Now()).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&fanOuts).Error
+		if err != nil || len(fanOuts) == 0 {
+			return err
+		}
+
+		var ids []string
+		for _, v := range fanOuts {
+			ids = append(ids, v.ID)
+		}
+		return tx.Model(&entities.ChannelFanOut{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fanOuts, nil
+}
+
+// SaveBatch writes the receipts of one batch and the progress of the fan-out in a single transaction,
+// renewing the lease so a long fan-out is not claimed by another worker halfway through
+func (r *channelFanOutRepository) SaveBatch(fanOut *entities.ChannelFanOut, messageStatuses []entities.MessageStatus, lease time.Duration) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(messageStatuses) > 0 {
+			if err := tx.CreateInBatches(messageStatuses, messageStatusBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&entities.ChannelFanOut{}).Where("id = ?", fanOut.ID).Updates(map[string]interface{}{
+			"last_user_id":    fanOut.LastUserID,
+			"status":          fanOut.Status,
+			"next_attempt_at": time.Now().Add(lease),
+		}).Error
+	})
+}
+
+// Reschedule records a failed attempt, the receipts already written stay
+func (r *channelFanOutRepository) Reschedule(fanOut *entities.ChannelFanOut) error {
+	return r.db.Model(&entities.ChannelFanOut{}).Where("id = ?", fanOut.ID).Updates(map[string]interface{}{
+		"attempts":        fanOut.Attempts,
+		"status":          fanOut.Status,
+		"next_attempt_at": fanOut.NextAttemptAt,
+	}).Error
+}
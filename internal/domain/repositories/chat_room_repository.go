@@ -3,20 +3,51 @@ package repositories
 import (
 	"chat-be/internal/domain/entities"
 	"errors"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type chatRoomRepository struct {
 	db *gorm.DB
 }
 
+// Room types a room list can be narrowed to
+const (
+	RoomTypeDirect  = "direct"
+	RoomTypeGroup   = "group"
+	RoomTypeChannel = "channel"
+)
+
+// RoomFilter narrows the rooms listed for a user, zero values leave the list unfiltered
+type RoomFilter struct {
+	Archived    bool
+	Name        string // Group name, or the other participant's username in direct rooms
+	Participant string // Username of any other participant
+	Type        string
+	UnreadOnly  bool
+}
+
 type ChatRoomRepository interface {
 	CreateRoom(room *entities.ChatRoom, participants []entities.ChatRoomParticipant) error
 	FindRoomByParticipants(userIDs []string) (*entities.ChatRoom, error)
-	FindRoomsByUser(userID string, offset int, limit int) ([]entities.ChatRoom, int64, error)
+	FindRoomsByUser(userID string, filter RoomFilter, offset int, limit int) ([]entities.ChatRoom, int64, error)
 	FindUsersByRoomID(roomID string) ([]entities.ChatRoomParticipant, error)
 	FindRoomByID(ID string) (*entities.ChatRoom, error)
+	FindRoom(ID string) (*entities.ChatRoom, error)
+	FindParticipantIDs(roomID, afterUserID string, limit int) ([]string, error)
+	FindParticipant(roomID, userID string) (*entities.ChatRoomParticipant, error)
+	SetMutedUntil(roomID, userID string, mutedUntil *time.Time) error
+	SetPinnedAt(roomID, userID string, pinnedAt *time.Time) error
+	SetArchivedAt(roomID, userID string, archivedAt *time.Time) error
+	CountPinned(userID string) (int64, error)
+	AddParticipants(participants []entities.ChatRoomParticipant) error
+	RemoveParticipant(roomID, userID string) error
+	UpdateParticipantRole(roomID, userID, role string) error
+	DeleteRoom(roomID string) error
+	UpdateRoomDetails(room *entities.ChatRoom) error
 }
 
 func NewChatRoomRepository(db *gorm.DB) ChatRoomRepository {
@@ -62,23 +93,60 @@ func (r *chatRoomRepository) FindRoomByParticipants(userIDs []string) (*entities
 	return &room, nil
 }
 
-func (r *chatRoomRepository) FindRoomsByUser(userID string, offset int, limit int) ([]entities.ChatRoom, int64, error) {
+func (r *chatRoomRepository) FindRoomsByUser(userID string, filter RoomFilter, offset int, limit int) ([]entities.ChatRoom, int64, error) {
 	var rooms []entities.ChatRoom
 	var totalRows int64
 
+	query := func() *gorm.DB {
+		q := r.db.Table("chat_rooms").
+			Joins("JOIN chat_room_participants c ON c.chat_room_id = chat_rooms.id").
+			Where("c.user_id = ? AND chat_rooms.deleted_at IS NULL", userID)
+		if filter.Archived {
+			q = q.Where("c.archived_at IS NOT NULL")
+		} else {
+			q = q.Where("c.archived_at IS NULL")
+		}
+
+		switch filter.Type {
+		case RoomTypeDirect:
+			q = q.Where("NOT chat_rooms.is_group")
+		case RoomTypeGroup:
+			q = q.Where("chat_rooms.is_group AND NOT chat_rooms.is_channel")
+		case RoomTypeChannel:
+			q = q.Where("chat_rooms.is_channel")
+		}
+
+		if filter.Name != "" {
+			pattern := likePattern(filter.Name)
+			q = q.Where(`(chat_rooms.is_group AND chat_rooms.name ILIKE ?) OR (NOT chat_rooms.is_group AND EXISTS (
+				SELECT 1 FROM chat_room_participants o JOIN users u ON u.id = o.user_id
+				WHERE o.chat_room_id = chat_rooms.id AND o.user_id <> ? AND u.username ILIKE ?))`, pattern, userID, pattern)
+		}
+		if filter.Participant != "" {
+			q = q.Where(`EXISTS (
+				SELECT 1 FROM chat_room_participants o JOIN users u ON u.id = o.user_id
+				WHERE o.chat_room_id = chat_rooms.id AND o.user_id <> ? AND u.username ILIKE ?)`, userID, likePattern(filter.Participant))
+		}
+		if filter.UnreadOnly {
+			q = q.Where(`EXISTS (
+				SELECT 1 FROM message_statuses s JOIN messages m ON m.id = s.message_id
+				WHERE m.chat_room_id = chat_rooms.id AND s.receiver_id = ? AND s.status < ? AND s.deleted_at IS NULL)`, userID, entities.StatusRead)
+		}
+		return q
+	}
+
 	// Count total rows
-	err := r.db.Table("chat_rooms").
-		Joins("JOIN chat_room_participants c ON c.chat_room_id = chat_rooms.id").
-		Where("c.user_id = ?", userID).
-		Count(&totalRows).Error
+	err := query().Count(&totalRows).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Query rooms with offset and limit
-	err = r.db.Joins("JOIN chat_room_participants c ON c.chat_room_id = chat_rooms.id").
-		Where("c.user_id = ?", userID).
+	// Pinned rooms first, then the rooms with the latest activity
+	err = query().
+		Select("chat_rooms.*").
 		Preload("Participants.User.SocketPath"). // Preload participants, users, and socket paths
+		Order("c.pinned_at IS NULL, c.pinned_at DESC").
+		Order("COALESCE((SELECT MAX(m.created_at) FROM messages m WHERE m.chat_room_id = chat_rooms.id), chat_rooms.created_at) DESC").
 		Offset(offset).
 		Limit(limit).
 		Find(&rooms).Error
@@ -101,6 +169,30 @@ func (r *chatRoomRepository) FindRoomByID(ID string) (*entities.ChatRoom, error)
 	return &room, nil
 }
 
+// FindRoom loads a room without its participants, for rooms that can have thousands of them
+func (r *chatRoomRepository) FindRoom(ID string) (*entities.ChatRoom, error) {
+	var room entities.ChatRoom
+	err := r.db.Where("id = ?", ID).Take(&room).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
+// FindParticipantIDs pages through the user IDs of a room in ID order, starting after afterUserID
+func (r *chatRoomRepository) FindParticipantIDs(roomID, afterUserID string, limit int) ([]string, error) {
+	var userIDs []string
+	query := r.db.Model(&entities.ChatRoomParticipant{}).Where("chat_room_id = ?", roomID)
+	if afterUserID != "" {
+		query = query.Where("user_id > ?", afterUserID)
+	}
+	err := query.Order("user_id").Limit(limit).Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
 func (r *chatRoomRepository) FindUsersByRoomID(roomID string) ([]entities.ChatRoomParticipant, error) {
 	var participants []entities.ChatRoomParticipant
 
@@ -111,3 +203,85 @@ func (r *chatRoomRepository) FindUsersByRoomID(roomID string) ([]entities.ChatRo
 
 	return participants, nil
 }
+
+func (r *chatRoomRepository) FindParticipant(roomID, userID string) (*entities.ChatRoomParticipant, error) {
+	var participant entities.ChatRoomParticipant
+	err := r.db.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&participant).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &participant, nil
+}
+
+func (r *chatRoomRepository) SetMutedUntil(roomID, userID string, mutedUntil *time.Time) error {
+	return r.db.Model(&entities.ChatRoomParticipant{}).
+		Where("chat_room_id = ? AND user_id = ?", roomID, userID).
+		Update("muted_until", mutedUntil).Error
+}
+
+func (r *chatRoomRepository) SetPinnedAt(roomID, userID string, pinnedAt *time.Time) error {
+	return r.db.Model(&entities.ChatRoomParticipant{}).
+		Where("chat_room_id = ? AND user_id = ?", roomID, userID).
+		Update("pinned_at", pinnedAt).Error
+}
+
+func (r *chatRoomRepository) SetArchivedAt(roomID, userID string, archivedAt *time.Time) error {
+	return r.db.Model(&entities.ChatRoomParticipant{}).
+		Where("chat_room_id = ? AND user_id = ?", roomID, userID).
+		Update("archived_at", archivedAt).Error
+}
+
+func (r *chatRoomRepository) CountPinned(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.ChatRoomParticipant{}).
+		Where("user_id = ? AND pinned_at IS NOT NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// AddParticipants skips users that are already in the room, so two concurrent joins add the user once
+func (r *chatRoomRepository) AddParticipants(participants []entities.ChatRoomParticipant) error {
+	if len(participants) == 0 {
+		return nil
+	}
+	return r.db.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "chat_room_id"}},
+		DoNothing: true,
+	}).Create(&participants).Error
+}
+
+func (r *chatRoomRepository) RemoveParticipant(roomID, userID string) error {
+	return r.db.Where("chat_room_id = ? AND user_id = ?", roomID, userID).Delete(&entities.ChatRoomParticipant{}).Error
+}
+
+func (r *chatRoomRepository) UpdateParticipantRole(roomID, userID, role string) error {
+	return r.db.Model(&entities.ChatRoomParticipant{}).
+		Where("chat_room_id = ? AND user_id = ?", roomID, userID).
+		Update("role", role).Error
+}
+
+func (r *chatRoomRepository) DeleteRoom(roomID string) error {
+	return r.db.Where("id = ?", roomID).Delete(&entities.ChatRoom{}).Error
+}
+
+func (r *chatRoomRepository) UpdateRoomDetails(room *entities.ChatRoom) error {
+	return r.db.Model(&entities.ChatRoom{}).Where("id = ?", room.ID).Updates(map[string]interface{}{
+		"name":        room.Name,
+		"description": room.Description,
+		"avatar":      room.Avatar,
+		"is_private":  room.IsPrivate,
+	}).Error
+}
+
+// likePattern matches text anywhere in a column
+func likePattern(text string) string {
+	return "%" + escapeLike(text) + "%"
+}
+
+// escapeLike makes LIKE wildcards in text match literally
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ContactRepository interface {
+	Create(contact *entities.Contact) error
+	FindByID(id string) (*entities.Contact, error)
+	FindBetween(userID, otherID string) (*entities.Contact, error)
+	FindAccepted(userID string) ([]entities.Contact, error)
+	FindPending(userID string) ([]entities.Contact, error)
+	Reopen(id, requesterID, addresseeID string) error
+	Respond(id, status string) (bool, error)
+	Delete(id string) error
+}
+
+type contactRepository struct {
+	db *gorm.DB
+}
+
+func NewContactRepository(db *gorm.DB) ContactRepository {
+	return &contactRepository{db}
+}
+
+func (r *contactRepository) Create(contact *entities.Contact) error {
+	return r.db.Omit("Requester", "Addressee").Create(contact).Error
+}
+
+func (r *contactRepository) FindByID(id string) (*entities.Contact, error) {
+	var contact entities.Contact
+	err := r.db.Preload("Requester.SocketPath").Preload("Addressee.SocketPath").Where("id = ?", id).First(&contact).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// FindBetween returns the contact row of the pair in either direction
+func (r *contactRepository) FindBetween(userID, otherID string) (*entities.Contact, error) {
+	var contact entities.Contact
+	err := r.db.Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)", userID, otherID, otherID, userID).
+		First(&contact).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &contact, nil
+}
+
+func (r *contactRepository) FindAccepted(userID string) ([]entities.Contact, error) {
+	var contacts []entities.Contact
+	err := r.db.Preload("Requester").Preload("Addressee").
+		Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, entities.ContactAccepted).
+		Order("responded_at DESC").
+		Find(&contacts).Error
+	if err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// FindPending returns the friend requests sent to and by the user that are still waiting for an answer
+func (r *contactRepository) FindPending(userID string) ([]entities.Contact, error) {
+	var contacts []entities.Contact
+	err := r.db.Preload("Requester").Preload("Addressee").
+		Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, entities.ContactPending).
+		Order("updated_at DESC").
+		Find(&contacts).Error
+	if err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// Reopen turns a declined pair into a new pending request from requesterID
+func (r *contactRepository) Reopen(id, requesterID, addresseeID string) error {
+	return r.db.Model(&entities.Contact{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"requester_id": requesterID,
+		"addressee_id": addresseeID,
+		"status":       entities.ContactPending,
+		"responded_at": nil,
+	}).Error
+}
+
+// Respond accepts or declines a pending request, it reports false when it was already answered
+func (r *contactRepository) Respond(id, status string) (bool, error) {
+	result := r.db.Model(&entities.Contact{}).
+		Where("id = ? AND status = ?", id, entities.ContactPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": time.Now()})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *contactRepository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&entities.Contact{}).Error
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeviceTokenRepository interface {
+	Save(deviceToken *entities.DeviceToken) error
+	FindByID(id string) (*entities.DeviceToken, error)
+	FindByUserID(userID string) ([]entities.DeviceToken, error)
+	Delete(id string) error
+	DeleteByToken(token string) error
+}
+
+type deviceTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceTokenRepository(db *gorm.DB) DeviceTokenRepository {
+	return &deviceTokenRepository{db}
+}
+
+// Save registers a token, a token already registered moves to the new user since the device changed hands
+func (r *deviceTokenRepository) Save(deviceToken *entities.DeviceToken) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "session_id", "platform", "updated_at"}),
+	}).Create(deviceToken).Error
+	if err != nil {
+		return err
+	}
+	// Reload so an existing registration keeps its ID
+	return r.db.Where("token = ?", deviceToken.Token).First(deviceToken).Error
+}
+
+func (r *deviceTokenRepository) FindByID(id string) (*entities.DeviceToken, error) {
+	var deviceToken entities.DeviceToken
+	err := r.db.Where("id = ?", id).First(&deviceToken).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deviceToken, nil
+}
+
+func (r *deviceTokenRepository) FindByUserID(userID string) ([]entities.DeviceToken, error) {
+	var deviceTokens []entities.DeviceToken
+	err := r.db.Where("user_id = ?", userID).Order("updated_at DESC").Find(&deviceTokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return deviceTokens, nil
+}
+
+func (r *deviceTokenRepository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&entities.DeviceToken{}).Error
+}
+
+func (r *deviceTokenRepository) DeleteByToken(token string) error {
+	return r.db.Where("token = ?", token).Delete(&entities.DeviceToken{}).Error
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExternalIdentityRepository interface {
+	Create(identity *entities.ExternalIdentity) error
+	FindByProviderSubject(provider, subject string) (*entities.ExternalIdentity, error)
+	CreateLoginState(state *entities.SSOLoginState) error
+	TakeLoginState(state string) (*entities.SSOLoginState, error)
+}
+
+type externalIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewExternalIdentityRepository(db *gorm.DB) ExternalIdentityRepository {
+	return &externalIdentityRepository{db}
+}
+
+func (r *externalIdentityRepository) Create(identity *entities.ExternalIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *externalIdentityRepository) FindByProviderSubject(provider, subject string) (*entities.ExternalIdentity, error) {
+	var identity entities.ExternalIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *externalIdentityRepository) CreateLoginState(state *entities.SSOLoginState) error {
+	return r.db.Create(state).Error
+}
+
+// TakeLoginState deletes and returns a login state in one statement so it can only be used once
+func (r *externalIdentityRepository) TakeLoginState(state string) (*entities.SSOLoginState, error) {
+	var loginState entities.SSOLoginState
+	result := r.db.Clauses(clause.Returning{}).Where("state = ?", state).Delete(&loginState)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &loginState, nil
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type JoinRequestRepository interface {
+	Create(joinRequest *entities.JoinRequest) error
+	FindByID(id string) (*entities.JoinRequest, error)
+	FindPending(roomID, userID string) (*entities.JoinRequest, error)
+	FindPendingByRoom(roomID string) ([]entities.JoinRequest, error)
+	Decide(id, status, decidedBy string) (bool, error)
+}
+
+type joinRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewJoinRequestRepository(db *gorm.DB) JoinRequestRepository {
+	return &joinRequestRepository{db}
+}
+
+func (r *joinRequestRepository) Create(joinRequest *entities.JoinRequest) error {
+	return r.db.Omit("User").Create(joinRequest).Error
+}
+
+func (r *joinRequestRepository) FindByID(id string) (*entities.JoinRequest, error) {
+	var joinRequest entities.JoinRequest
+	err := r.db.Preload("User.SocketPath").Where("id = ?", id).First(&joinRequest).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &joinRequest, nil
+}
+
+func (r *joinRequestRepository) FindPending(roomID, userID string) (*entities.JoinRequest, error) {
+	var joinRequest entities.JoinRequest
+	err := r.db.Where("chat_room_id = ? AND user_id = ? AND status = ?", roomID, userID, entities.JoinRequestPending).First(&joinRequest).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &joinRequest, nil
+}
+
+func (r *joinRequestRepository) FindPendingByRoom(roomID string) ([]entities.JoinRequest, error) {
+	var joinRequests []entities.JoinRequest
+	err := r.db.Preload("User").
+		Where("chat_room_id = ? AND status = ?", roomID, entities.JoinRequestPending).
+		Order("created_at ASC").
+		Find(&joinRequests).Error
+	if err != nil {
+		return nil, err
+	}
+	return joinRequests, nil
+}
+
+// Decide settles a pending request, it reports false when another admin already decided it
+func (r *joinRequestRepository) Decide(id, status, decidedBy string) (bool, error) {
+	result := r.db.Model(&entities.JoinRequest{}).
+		Where("id = ? AND status = ?", id, entities.JoinRequestPending).
+		Updates(map[string]interface{}{"status": status, "decided_by": decidedBy, "decided_at": time.Now()})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
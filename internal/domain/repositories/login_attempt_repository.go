@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepository interface {
+	FindByKey(key string) (*entities.LoginAttempt, error)
+	IncrementFailure(key string, now time.Time, window time.Duration) (*entities.LoginAttempt, error)
+	LockUntil(key string, lockedUntil time.Time) error
+	Delete(key string) error
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db}
+}
+
+func (r *loginAttemptRepository) FindByKey(key string) (*entities.LoginAttempt, error) {
+	var attempt entities.LoginAttempt
+	err := r.db.Where("key = ?", key).First(&attempt).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// IncrementFailure counts a failure in a single statement so concurrent attempts are never lost.
+// A counter whose last failure is older than window starts over at 1
+func (r *loginAttemptRepository) IncrementFailure(key string, now time.Time, window time.Duration) (*entities.LoginAttempt, error) {
+	staleBefore := now.Add(-window)
+	attempt := entities.LoginAttempt{Key: key, FailedCount: 1, LastFailedAt: now}
+	err := r.db.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "failed_count"}, Value: gorm.Expr("CASE WHEN login_attempts.last_failed_at < ? THEN 1 ELSE login_attempts.failed_count + 1 END", staleBefore)},
+				{Column: clause.Column{Name: "locked_until"}, Value: gorm.Expr("CASE WHEN login_attempts.last_failed_at < ? THEN NULL ELSE login_attempts.locked_until END", staleBefore)},
+				{Column: clause.Column{Name: "last_failed_at"}, Value: now},
+				{Column: clause.Column{Name: "updated_at"}, Value: now},
+			},
+		},
+		clause.Returning{},
+	).Create(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// LockUntil never shortens a lock set by a concurrent failure with a higher count
+func (r *loginAttemptRepository) LockUntil(key string, lockedUntil time.Time) error {
+	return r.db.Model(&entities.LoginAttempt{}).
+		Where("key = ?", key).
+		Update("locked_until", gorm.Expr("GREATEST(locked_until, ?)", lockedUntil)).Error
+}
+
+func (r *loginAttemptRepository) Delete(key string) error {
+	return r.db.Where("key = ?", key).Delete(&entities.LoginAttempt{}).Error
+}
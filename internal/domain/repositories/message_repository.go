@@ -3,18 +3,43 @@ package repositories
 
 import (
 	"chat-be/internal/domain/entities"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type MessageRepository interface {
 	Create(message *entities.Message) error
+	FindByID(id string) (*entities.Message, error)
 	SaveMessage(message *entities.Message) error
 	CreateMessageStatus(messageStatus *entities.MessageStatus) error
-	GetMessagesByRoomID(chatRoomID string, offset int, limit int) ([]entities.Message, int64, error)
+	CreateMessageStatuses(messageStatuses []entities.MessageStatus) error
+	GetMessagesByRoomID(chatRoomID, viewerID string, offset int, limit int) ([]entities.Message, int64, error)
 	UpdateMessageStatus(messageID string, receiverID string, status int) error
+	SearchMessages(userID string, query MessageSearchQuery) ([]MessageSearchResult, error)
 }
 
+// MessageSearchQuery looks up text messages in the rooms of a user, newest first.
+// Results continue after the message at (BeforeTime, BeforeID) when BeforeID is set.
+type MessageSearchQuery struct {
+	Text       string
+	RoomID     string
+	BeforeTime time.Time
+	BeforeID   string
+	Limit      int
+}
+
+type MessageSearchResult struct {
+	ID         string
+	ChatRoomID string
+	SenderID   string
+	Content    string
+	Snippet    string
+	CreatedAt  time.Time
+}
+
+const messageStatusBatchSize = 500
+
 type messageRepository struct {
 	db *gorm.DB
 }
@@ -27,6 +52,18 @@ func (r *messageRepository) Create(message *entities.Message) error {
 	return r.db.Create(message).Error
 }
 
+func (r *messageRepository) FindByID(id string) (*entities.Message, error) {
+	var message entities.Message
+	err := r.db.Where("id = ?", id).First(&message).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
 func (r *messageRepository) SaveMessage(message *entities.Message) error {
 	var existingMessage entities.Message
 	err := r.db.Where("id = ?", message.ID).First(&existingMessage).Error
@@ -49,6 +86,14 @@ func (r *messageRepository) CreateMessageStatus(messageStatus *entities.MessageS
 	return r.db.Create(messageStatus).Error
 }
 
+// CreateMessageStatuses inserts the receipts of one message in batches instead of one row per query
+func (r *messageRepository) CreateMessageStatuses(messageStatuses []entities.MessageStatus) error {
+	if len(messageStatuses) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(messageStatuses, messageStatusBatchSize).Error
+}
+
 func (r *messageRepository) GetMessageStatus(messageID string, receiverID string) (*entities.MessageStatus, error) {
 	var messageStatus entities.MessageStatus
 	err := r.db.Where("message_id = ? AND receiver_id = ?", messageID, receiverID).First(&messageStatus).Error
@@ -65,13 +110,18 @@ func (r *messageRepository) UpdateMessageStatus(messageID string, receiverID str
 		Update("status", status).Error
 }
 
-func (r *messageRepository) GetMessagesByRoomID(chatRoomID string, offset int, limit int) ([]entities.Message, int64, error) {
+// GetMessagesByRoomID pages through a room's history as seen by viewerID, leaving out what users the viewer blocked wrote
+func (r *messageRepository) GetMessagesByRoomID(chatRoomID, viewerID string, offset int, limit int) ([]entities.Message, int64, error) {
 	var messages []entities.Message
 	var totalRows int64
 
+	notBlocked := r.db.Where("messages.type = ?", entities.MessageTypeSystem).
+		Or("NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.blocker_id = ? AND b.blocked_id = messages.sender_id)", viewerID)
+
 	// Count total rows
 	err := r.db.Model(&entities.Message{}).
 		Where("chat_room_id = ?", chatRoomID).
+		Where(notBlocked).
 		Count(&totalRows).Error
 	if err != nil {
 		return nil, 0, err
@@ -80,6 +130,7 @@ func (r *messageRepository) GetMessagesByRoomID(chatRoomID string, offset int, l
 	// Query messages with offset, limit, and preload MessageStatus
 	err = r.db.Preload("MessageStatus").
 		Where("chat_room_id = ?", chatRoomID).
+		Where(notBlocked).
 		Offset(offset).
 		Limit(limit).
 		Find(&messages).Error
@@ -89,3 +140,33 @@ func (r *messageRepository) GetMessagesByRoomID(chatRoomID string, offset int, l
 
 	return messages, totalRows, nil
 }
+
+// SearchMessages matches messages.content with Postgres full-text search, served by the
+// idx_messages_content_fts GIN index, and highlights the matches in a snippet
+func (r *messageRepository) SearchMessages(userID string, query MessageSearchQuery) ([]MessageSearchResult, error) {
+	var results []MessageSearchResult
+
+	db := r.db.Table("messages m").
+		Select(`m.id, m.chat_room_id, m.sender_id, m.content, m.created_at,
+			ts_headline('simple', m.content, websearch_to_tsquery('simple', ?), 'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5, MaxFragments=1') AS snippet`, query.Text).
+		Where("to_tsvector('simple', m.content) @@ websearch_to_tsquery('simple', ?)", query.Text).
+		Where("m.type = ? AND m.deleted_at IS NULL", entities.MessageTypeText).
+		// System messages are never searched, so unlike the history there is no exception for them
+		Where("NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.blocker_id = ? AND b.blocked_id = m.sender_id)", userID).
+		Where("m.chat_room_id IN (?)", r.db.Table("chat_room_participants").Select("chat_room_id").Where("user_id = ?", userID))
+	if query.RoomID != "" {
+		db = db.Where("m.chat_room_id = ?", query.RoomID)
+	}
+	if query.BeforeID != "" {
+		db = db.Where("(m.created_at, m.id) < (?, ?)", query.BeforeTime, query.BeforeID)
+	}
+
+	err := db.Order("m.created_at DESC, m.id DESC").
+		Limit(query.Limit).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	Create(token *entities.PasswordResetToken) error
+	FindByTokenHash(tokenHash string) (*entities.PasswordResetToken, error)
+	ResetPassword(token *entities.PasswordResetToken, hashedPassword string) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db}
+}
+
+func (r *passwordResetRepository) Create(token *entities.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *passwordResetRepository) FindByTokenHash(tokenHash string) (*entities.PasswordResetToken, error) {
+	var token entities.PasswordResetToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *passwordResetRepository) ResetPassword(token *entities.PasswordResetToken, hashedPassword string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Mark the token as used, guarding against a concurrent reset with the same token
+		result := tx.Model(&entities.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Model(&entities.User{}).Where("id = ?", token.UserID).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+
+		// Any other outstanding tokens for the user are no longer valid
+		return tx.Model(&entities.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", time.Now()).Error
+	})
+}
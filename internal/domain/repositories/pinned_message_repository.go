@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PinnedMessageRepository interface {
+	CreateWithinLimit(pin *entities.PinnedMessage, limit int) (bool, error)
+	Find(roomID, messageID string) (*entities.PinnedMessage, error)
+	FindByRoomID(roomID, viewerID string) ([]entities.PinnedMessage, error)
+	Delete(roomID, messageID string) (bool, error)
+}
+
+type pinnedMessageRepository struct {
+	db *gorm.DB
+}
+
+func NewPinnedMessageRepository(db *gorm.DB) PinnedMessageRepository {
+	return &pinnedMessageRepository{db}
+}
+
+// CreateWithinLimit pins a message unless the room already has limit pins and reports whether it did.
+// The room row is locked while counting so concurrent pins cannot both pass the limit
+func (r *pinnedMessageRepository) CreateWithinLimit(pin *entities.PinnedMessage, limit int) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var room entities.ChatRoom
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", pin.ChatRoomID).First(&room).Error
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&entities.PinnedMessage{}).Where("chat_room_id = ?", pin.ChatRoomID).Count(&count).Error
+		if err != nil || count >= int64(limit) {
+			return err
+		}
+
+		created = true
+		return tx.Omit("Message").Create(pin).Error
+	})
+	return created && err == nil, err
+}
+
+func (r *pinnedMessageRepository) Find(roomID, messageID string) (*entities.PinnedMessage, error) {
+	var pin entities.PinnedMessage
+	err := r.db.Where("chat_room_id = ? AND message_id = ?", roomID, messageID).First(&pin).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pin, nil
+}
+
+// FindByRoomID returns the pins of a room with their messages, latest pin first,
+// leaving out messages written by users viewerID blocked
+func (r *pinnedMessageRepository) FindByRoomID(roomID, viewerID string) ([]entities.PinnedMessage, error) {
+	var pins []entities.PinnedMessage
+	err := r.db.Preload("Message").
+		Where("chat_room_id = ?", roomID).
+		Where(`NOT EXISTS (SELECT 1 FROM messages m JOIN user_blocks b ON b.blocked_id = m.sender_id
+			WHERE m.id = pinned_messages.message_id AND b.blocker_id = ?)`, viewerID).
+		Order("created_at DESC").
+		Find(&pins).Error
+	return pins, err
+}
+
+// Delete removes a pin and reports whether there was one to remove
+func (r *pinnedMessageRepository) Delete(roomID, messageID string) (bool, error) {
+	result := r.db.Where("chat_room_id = ? AND message_id = ?", roomID, messageID).Delete(&entities.PinnedMessage{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository interface {
+	ReplaceForUser(userID string, codes []entities.RecoveryCode) error
+	FindUnusedByUser(userID string) ([]entities.RecoveryCode, error)
+	MarkUsed(id string) (bool, error)
+	DeleteByUser(userID string) error
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db}
+}
+
+func (r *recoveryCodeRepository) ReplaceForUser(userID string, codes []entities.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) > 0 {
+			if err := tx.Create(&codes).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *recoveryCodeRepository) FindUnusedByUser(userID string) ([]entities.RecoveryCode, error) {
+	var codes []entities.RecoveryCode
+	err := r.db.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// MarkUsed consumes a recovery code, reporting false when it was already used
+func (r *recoveryCodeRepository) MarkUsed(id string) (bool, error) {
+	result := r.db.Model(&entities.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *recoveryCodeRepository) DeleteByUser(userID string) error {
+	return r.db.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error
+}
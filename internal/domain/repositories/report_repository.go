@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+
+	"gorm.io/gorm"
+)
+
+type ReportRepository interface {
+	Create(report *entities.Report) error
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db}
+}
+
+// Create stores the report together with its evidence
+func (r *reportRepository) Create(report *entities.Report) error {
+	return r.db.Create(report).Error
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RoomInviteRepository interface {
+	Create(invite *entities.RoomInvite) error
+	FindByID(id string) (*entities.RoomInvite, error)
+	FindByToken(token string) (*entities.RoomInvite, error)
+	FindByRoomID(roomID string) ([]entities.RoomInvite, error)
+	Revoke(id string) error
+	Use(id string) (bool, error)
+}
+
+type roomInviteRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomInviteRepository(db *gorm.DB) RoomInviteRepository {
+	return &roomInviteRepository{db}
+}
+
+func (r *roomInviteRepository) Create(invite *entities.RoomInvite) error {
+	return r.db.Create(invite).Error
+}
+
+func (r *roomInviteRepository) FindByID(id string) (*entities.RoomInvite, error) {
+	var invite entities.RoomInvite
+	err := r.db.Where("id = ?", id).First(&invite).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *roomInviteRepository) FindByToken(token string) (*entities.RoomInvite, error) {
+	var invite entities.RoomInvite
+	err := r.db.Where("token = ?", token).First(&invite).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *roomInviteRepository) FindByRoomID(roomID string) ([]entities.RoomInvite, error) {
+	var invites []entities.RoomInvite
+	err := r.db.Where("chat_room_id = ?", roomID).Order("created_at DESC").Find(&invites).Error
+	if err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+func (r *roomInviteRepository) Revoke(id string) error {
+	return r.db.Model(&entities.RoomInvite{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// Use counts a join against the invite, it reports false when the invite is revoked, expired or used up
+// so two people racing for the last use cannot both get in
+func (r *roomInviteRepository) Use(id string) (bool, error) {
+	result := r.db.Model(&entities.RoomInvite{}).
+		Where("id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?) AND (max_uses = 0 OR uses < max_uses)", id, time.Now()).
+		Update("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(session *entities.Session) error
+	FindByID(id string) (*entities.Session, error)
+	FindActiveByUser(userID string) ([]entities.Session, error)
+	TouchLastSeen(id string, lastSeenAt time.Time) error
+	Revoke(id string) error
+	RevokeAllByUser(userID string, exceptID string) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db}
+}
+
+func (r *sessionRepository) Create(session *entities.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) FindByID(id string) (*entities.Session, error) {
+	var session entities.Session
+	err := r.db.Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) FindActiveByUser(userID string) ([]entities.Session, error) {
+	var sessions []entities.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepository) TouchLastSeen(id string, lastSeenAt time.Time) error {
+	return r.db.Model(&entities.Session{}).Where("id = ?", id).Update("last_seen_at", lastSeenAt).Error
+}
+
+// Revoke signs the session out and removes the push tokens it registered
+func (r *sessionRepository) Revoke(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("session_id = ?", id).Delete(&entities.DeviceToken{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&entities.Session{}).
+			Where("id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", time.Now()).Error
+	})
+}
+
+// RevokeAllByUser revokes every active session of the user, keeping exceptID when it is not empty.
+// Push tokens registered from the revoked sessions are removed with them
+func (r *sessionRepository) RevokeAllByUser(userID string, exceptID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		active := func() *gorm.DB {
+			query := tx.Model(&entities.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+			if exceptID != "" {
+				query = query.Where("id <> ?", exceptID)
+			}
+			return query
+		}
+
+		err := tx.Where("session_id IN (?)", active().Select("id")).Delete(&entities.DeviceToken{}).Error
+		if err != nil {
+			return err
+		}
+		return active().Update("revoked_at", time.Now()).Error
+	})
+}
@@ -2,16 +2,35 @@ package repositories
 
 import (
 	"chat-be/internal/domain/entities"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	Create(user *entities.User) error
 	FindByEmail(email string) (*entities.User, error)
 	FindByID(id string) (*entities.User, error)
+	FindByUsername(username string) (*entities.User, error)
+	Update(user *entities.User) error
+	FindBotsByOwner(ownerID string) ([]entities.User, error)
 	CountUsersBySocketID(socketID string) (int64, error)
-	SearchByUsernameOrEmail(query string) ([]*entities.User, error)
+	SearchUsers(query UserSearchQuery) ([]entities.User, int64, error)
+	UpdatePresence(userID string, online bool, lastSeenAt time.Time) error
+	FindStaleOnline(before time.Time) ([]entities.User, error)
+	FindDirectContacts(userID string) ([]entities.User, error)
+}
+
+// UserSearchQuery ranks users by an exact username or email match first, then a username prefix,
+// then trigram similarity. Users who are not discoverable only show up for exact matches,
+// the searcher and users who blocked the searcher never do.
+type UserSearchQuery struct {
+	Text       string
+	SearcherID string
+	Offset     int
+	Limit      int
 }
 
 type userRepository struct {
@@ -47,17 +66,97 @@ func (r *userRepository) FindByID(id string) (*entities.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) FindByUsername(username string) (*entities.User, error) {
+	var user entities.User
+	err := r.db.Preload("SocketPath").Where("username = ?", username).First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Update(user *entities.User) error {
+	// Presence is owned by the gateway events, a profile save must not overwrite it with a stale copy
+	return r.db.Omit("SocketPath", "Online", "LastSeenAt").Save(user).Error
+}
+
+func (r *userRepository) FindBotsByOwner(ownerID string) ([]entities.User, error) {
+	var bots []entities.User
+	err := r.db.Where("is_bot = ? AND owner_id = ?", true, ownerID).Order("created_at DESC").Find(&bots).Error
+	if err != nil {
+		return nil, err
+	}
+	return bots, nil
+}
+
 func (r *userRepository) CountUsersBySocketID(socketID string) (int64, error) {
 	var count int64
 	err := r.db.Model(&entities.User{}).Where("socket_id = ?", socketID).Count(&count).Error
 	return count, err
 }
 
-func (r *userRepository) SearchByUsernameOrEmail(query string) ([]*entities.User, error) {
-	var users []*entities.User
-	err := r.db.Preload("SocketPath").
-		Where("username LIKE ? OR email LIKE ?", "%"+query+"%", "%"+query+"%").
+func (r *userRepository) SearchUsers(query UserSearchQuery) ([]entities.User, int64, error) {
+	var users []entities.User
+	var total int64
+
+	text := strings.ToLower(query.Text)
+	prefix := escapeLike(text) + "%"
+	match := func() *gorm.DB {
+		return r.db.Model(&entities.User{}).
+			Where("id <> ?", query.SearcherID).
+			Where("NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.blocker_id = users.id AND b.blocked_id = ?)", query.SearcherID).
+			Where(`LOWER(username) = ? OR LOWER(email) = ? OR (discoverable AND (username ILIKE ? OR username % ?))`, text, text, prefix, text)
+	}
+
+	err := match().Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = match().
+		Preload("SocketPath").
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "CASE WHEN LOWER(username) = ? OR LOWER(email) = ? THEN 0 WHEN username ILIKE ? THEN 1 ELSE 2 END, similarity(username, ?) DESC, username",
+			Vars: []interface{}{text, text, prefix, text},
+		}}).
+		Offset(query.Offset).
+		Limit(query.Limit).
 		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *userRepository) UpdatePresence(userID string, online bool, lastSeenAt time.Time) error {
+	return r.db.Model(&entities.User{}).Where("id = ?", userID).
+		UpdateColumns(map[string]interface{}{"online": online, "last_seen_at": lastSeenAt}).Error
+}
+
+// FindStaleOnline returns users still marked online whose gateway stopped sending heartbeats
+func (r *userRepository) FindStaleOnline(before time.Time) ([]entities.User, error) {
+	var users []entities.User
+	err := r.db.Where("online = ? AND (last_seen_at IS NULL OR last_seen_at < ?)", true, before).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindDirectContacts returns the users that share a direct room with userID
+func (r *userRepository) FindDirectContacts(userID string) ([]entities.User, error) {
+	subQuery := r.db.Table("chat_room_participants AS self").
+		Select("other.user_id").
+		Joins("JOIN chat_room_participants AS other ON other.chat_room_id = self.chat_room_id").
+		Joins("JOIN chat_rooms ON chat_rooms.id = self.chat_room_id").
+		Where("self.user_id = ? AND other.user_id <> ? AND chat_rooms.is_group = ? AND chat_rooms.deleted_at IS NULL", userID, userID, false)
+
+	var users []entities.User
+	err := r.db.Preload("SocketPath").Where("id IN (?)", subQuery).Find(&users).Error
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"chat-be/internal/domain/entities"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	Create(webhook *entities.Webhook) error
+	FindByID(id string) (*entities.Webhook, error)
+	FindByRoomID(roomID string) ([]entities.Webhook, error)
+	Delete(id string) error
+	CreateDeliveries(deliveries []entities.WebhookDelivery) error
+	FindDeliveriesByWebhookID(webhookID string, offset int, limit int) ([]entities.WebhookDelivery, int64, error)
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]entities.WebhookDelivery, error)
+	UpdateDelivery(delivery *entities.WebhookDelivery) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db}
+}
+
+func (r *webhookRepository) Create(webhook *entities.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *webhookRepository) FindByID(id string) (*entities.Webhook, error) {
+	var webhook entities.Webhook
+	err := r.db.Where("id = ?", id).First(&webhook).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) FindByRoomID(roomID string) ([]entities.Webhook, error) {
+	var webhooks []entities.Webhook
+	err := r.db.Where("chat_room_id = ?", roomID).Order("created_at").Find(&webhooks).Error
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&entities.Webhook{}).Error
+}
+
+func (r *webhookRepository) CreateDeliveries(deliveries []entities.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Omit("Webhook").Create(&deliveries).Error
+}
+
+func (r *webhookRepository) FindDeliveriesByWebhookID(webhookID string, offset int, limit int) ([]entities.WebhookDelivery, int64, error) {
+	var deliveries []entities.WebhookDelivery
+	var totalRows int64
+
+	err := r.db.Model(&entities.WebhookDelivery{}).Where("webhook_id = ?", webhookID).Count(&totalRows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = r.db.Where("webhook_id = ?", webhookID).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, totalRows, nil
+}
+
+// ClaimDueDeliveries locks pending deliveries that are due and pushes their next attempt past the lease,
+// so several workers can run without sending the same delivery twice
+func (r *webhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]entities.WebhookDelivery, error) {
+	var deliveries []entities.WebhookDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entities.WebhookDeliveryPending, time.Now()).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		var ids []string
+		for _, v := range deliveries {
+			ids = append(ids, v.ID)
+		}
+		return tx.Model(&entities.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the webhooks outside the locking query, deleted webhooks stay empty
+	for i := range deliveries {
+		var webhook entities.Webhook
+		err := r.db.Where("id = ?", deliveries[i].WebhookID).First(&webhook).Error
+		if err == nil {
+			deliveries[i].Webhook = webhook
+		} else if err != gorm.ErrRecordNotFound {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *entities.WebhookDelivery) error {
+	return r.db.Omit("Webhook").Save(delivery).Error
+}
@@ -0,0 +1,74 @@
+package kafka
+
+import (
+	"chat-be/internal/config"
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/usecases"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher writes real-time events to the topic consumed by the WebSocket gateway
+type KafkaPublisher struct {
+	Writer *kafka.Writer
+}
+
+func NewKafkaPublisher() usecases.EventPublisher {
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(config.GetEnv("KAFKA_HOST", "localhost:9092")),
+		Topic:        config.GetEnv("KAFKA_EVENT_TOPIC", "chat-events"),
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: 10 * time.Millisecond,
+	}
+	return &KafkaPublisher{Writer: writer}
+}
+
+func (p *KafkaPublisher) Publish(event usecases.Event) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Keyed by type so the gateway can route events the same way it reads the chat topic
+	return p.Writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.Type),
+		Value: value,
+	})
+}
+
+// KafkaNotificationPublisher writes notifications to the unread-notifications topic read by the notification service
+type KafkaNotificationPublisher struct {
+	Writer *kafka.Writer
+}
+
+func NewKafkaNotificationPublisher() usecases.NotificationPublisher {
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(config.GetEnv("KAFKA_HOST", "localhost:9092")),
+		Topic:        config.GetEnv("KAFKA_NOTIFICATION_TOPIC", "unread-notifications"),
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: 10 * time.Millisecond,
+	}
+	return &KafkaNotificationPublisher{Writer: writer}
+}
+
+func (p *KafkaNotificationPublisher) PublishNotification(notification models.Notification) error {
+	value, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Keyed by user so notifications of one user stay in order
+	return p.Writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(notification.UserID),
+		Value: value,
+	})
+}
@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"chat-be/internal/config"
+	"chat-be/internal/delivery/http/models"
 	"chat-be/internal/domain/entities"
 	"chat-be/internal/usecases"
 	"chat-be/package/logging"
@@ -13,17 +14,20 @@ import (
 )
 
 type KafkaService struct {
-	Reader         *kafka.Reader
-	MessageUsecase usecases.MessageUsecase
+	Reader              *kafka.Reader
+	MessageUsecase      usecases.MessageUsecase
+	PresenceUsecase     usecases.PresenceUsecase
+	TypingUsecase       usecases.TypingUsecase
+	NotificationUsecase usecases.NotificationUsecase
 }
 
-func NewKafkaService(messageUsecase usecases.MessageUsecase) *KafkaService {
+func NewKafkaService(messageUsecase usecases.MessageUsecase, presenceUsecase usecases.PresenceUsecase, typingUsecase usecases.TypingUsecase, notificationUsecase usecases.NotificationUsecase) *KafkaService {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: []string{config.GetEnv("KAFKA_HOST", "localhost:9092")},
 		Topic:   config.GetEnv("KAFKA_TOPIC", "chat"),
 		GroupID: "chat-be-group",
 	})
-	return &KafkaService{Reader: reader, MessageUsecase: messageUsecase}
+	return &KafkaService{Reader: reader, MessageUsecase: messageUsecase, PresenceUsecase: presenceUsecase, TypingUsecase: typingUsecase, NotificationUsecase: notificationUsecase}
 }
 
 func (k *KafkaService) checkKafkaConnection(ctx context.Context) error {
@@ -90,6 +94,34 @@ func (k *KafkaService) ConsumeMessage() {
 				logging.LogError(loopCtx, "Error while updating message status: %v", err)
 				continue
 			}
+		case "presence":
+			var presenceModel models.PresenceEvent
+			if err := json.Unmarshal(msg.Value, &presenceModel); err != nil {
+				logging.LogError(loopCtx, "Failed to parse presence: %v", err)
+				continue
+			}
+
+			if err := k.PresenceUsecase.HandlePresence(presenceModel); err != nil {
+				logging.LogError(loopCtx, "Error while updating presence: %v", err)
+				continue
+			}
+
+			if presenceModel.Status == usecases.PresenceConnect {
+				if err := k.NotificationUsecase.FlushPending(presenceModel.UserID); err != nil {
+					logging.LogError(loopCtx, "Error while flushing pending notifications: %v", err)
+				}
+			}
+		case "typing":
+			var typingModel models.TypingEvent
+			if err := json.Unmarshal(msg.Value, &typingModel); err != nil {
+				logging.LogError(loopCtx, "Failed to parse typing: %v", err)
+				continue
+			}
+
+			if err := k.TypingUsecase.HandleTyping(typingModel); err != nil {
+				logging.LogError(loopCtx, "Error while forwarding typing: %v", err)
+				continue
+			}
 		default:
 			logging.LogError(loopCtx, "Unknown message key: %v", key)
 			continue
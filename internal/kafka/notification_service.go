@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"chat-be/internal/config"
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/usecases"
+	"chat-be/package/logging"
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+// NotificationService consumes the unread-notifications topic and stores notifications for offline users
+type NotificationService struct {
+	Reader              *kafka.Reader
+	NotificationUsecase usecases.NotificationUsecase
+}
+
+func NewNotificationService(notificationUsecase usecases.NotificationUsecase) *NotificationService {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{config.GetEnv("KAFKA_HOST", "localhost:9092")},
+		Topic:   config.GetEnv("KAFKA_NOTIFICATION_TOPIC", "unread-notifications"),
+		GroupID: "chat-be-notification-group",
+	})
+	return &NotificationService{Reader: reader, NotificationUsecase: notificationUsecase}
+}
+
+func (n *NotificationService) ConsumeNotifications() {
+	ctx := context.Background()
+
+	for {
+		requestID := uuid.New().String()
+		loopCtx := context.WithValue(ctx, logging.RequestIDKey, requestID)
+
+		msg, err := n.Reader.ReadMessage(loopCtx)
+		if err != nil {
+			logging.LogError(loopCtx, "Error while reading notification: %v", err)
+			logging.LogError(loopCtx, "Stopping notification consumption due to Kafka error.")
+			return
+		}
+
+		var notification models.Notification
+		if err := json.Unmarshal(msg.Value, &notification); err != nil {
+			logging.LogError(loopCtx, "Failed to parse notification: %v", err)
+			continue
+		}
+
+		if err := n.NotificationUsecase.Notify(notification); err != nil {
+			logging.LogError(loopCtx, "Error while storing notification: %v", err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Mailer delivers plain text emails to users
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// outboxMailer appends every email to a local file instead of sending it,
+// so the flows that depend on email can be exercised during development
+type outboxMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewOutboxMailer(path string) Mailer {
+	return &outboxMailer{path: path}
+}
+
+func (m *outboxMailer) Send(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open outbox: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n----------\n\n", time.Now().Format(time.RFC1123Z), to, subject, body)
+	if err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProductionURL = "https://api.push.apple.com"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com"
+	// Apple rejects provider tokens older than an hour and throttles refreshing more than every 20 minutes
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+type APNsConfig struct {
+	KeyID  string
+	TeamID string
+	// Topic is the bundle ID of the app
+	Topic   string
+	Sandbox bool
+	// BaseURL overrides the APNs endpoint, only used by tests
+	BaseURL string
+}
+
+// APNsProvider sends to Apple devices with token based authentication
+type APNsProvider struct {
+	config APNsConfig
+	key    *ecdsa.PrivateKey
+	client *http.Client
+
+	mu        sync.Mutex
+	authToken string
+	issuedAt  time.Time
+}
+
+// NewAPNsProvider takes the .p8 signing key downloaded from the Apple developer portal
+func NewAPNsProvider(config APNsConfig, keyPEM []byte) (*APNsProvider, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid apns key: %w", err)
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = apnsProductionURL
+		if config.Sandbox {
+			config.BaseURL = apnsSandboxURL
+		}
+	}
+	return &APNsProvider{config: config, key: key, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+func (p *APNsProvider) Send(ctx context.Context, message Message) error {
+	authToken, err := p.token()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"sound": "default",
+		},
+	}
+	for k, v := range message.Data {
+		payload[k] = v
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.BaseURL+"/3/device/"+message.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+authToken)
+	req.Header.Set("apns-topic", p.config.Topic)
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(resp.Body).Decode(&failure)
+	if resp.StatusCode == http.StatusGone || failure.Reason == "BadDeviceToken" || failure.Reason == "Unregistered" || failure.Reason == "DeviceTokenNotForTopic" {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("apns responded %d: %s", resp.StatusCode, failure.Reason)
+}
+
+func (p *APNsProvider) token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.authToken != "" && time.Since(p.issuedAt) < apnsTokenLifetime {
+		return p.authToken, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.config.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.config.KeyID
+
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+	p.authToken = signed
+	p.issuedAt = now
+	return signed, nil
+}
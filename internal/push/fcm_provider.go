@@ -0,0 +1,178 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	fcmScope   = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmBaseURL = "https://fcm.googleapis.com"
+)
+
+// FCMConfig holds a Firebase service account, as downloaded from the Firebase console
+type FCMConfig struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+	// BaseURL overrides the FCM endpoint, only used by tests
+	BaseURL string `json:"-"`
+}
+
+// LoadFCMConfig reads a service account JSON file
+func LoadFCMConfig(path string) (FCMConfig, error) {
+	var config FCMConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(data, &config)
+	return config, err
+}
+
+// FCMProvider sends through the Firebase Cloud Messaging HTTP v1 API
+type FCMProvider struct {
+	config FCMConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewFCMProvider(config FCMConfig) *FCMProvider {
+	if config.BaseURL == "" {
+		config.BaseURL = fcmBaseURL
+	}
+	return &FCMProvider{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p *FCMProvider) Send(ctx context.Context, message Message) error {
+	accessToken, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": message.Token,
+			"notification": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"data": message.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/messages:send", p.config.BaseURL, p.config.ProjectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&failure)
+	for _, v := range failure.Error.Details {
+		// INVALID_ARGUMENT is also returned for a bad payload, so it does not prove the token is dead
+		if v.ErrorCode == "UNREGISTERED" {
+			return ErrInvalidToken
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("fcm responded %d: %s", resp.StatusCode, failure.Error.Message)
+}
+
+// token exchanges a signed service account assertion for an access token, cached until shortly before expiry
+func (p *FCMProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(p.config.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid fcm private key: %w", err)
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.config.ClientEmail,
+		"scope": fcmScope,
+		"aud":   p.config.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK || tokenResponse.AccessToken == "" {
+		return "", errors.New("failed to obtain fcm access token")
+	}
+
+	p.accessToken = tokenResponse.AccessToken
+	p.expiresAt = now.Add(time.Duration(tokenResponse.ExpiresIn)*time.Second - time.Minute)
+	return p.accessToken, nil
+}
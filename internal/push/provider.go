@@ -0,0 +1,52 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrInvalidToken is returned when the provider reports the device token as expired or unknown,
+// the token should be removed so it is not retried
+var ErrInvalidToken = errors.New("push token is no longer valid")
+
+type Message struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Provider delivers a push message to a single device
+type Provider interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// FakeProvider records messages instead of sending them, tokens listed in Invalid are rejected
+type FakeProvider struct {
+	mu      sync.Mutex
+	Sent    []Message
+	Invalid map[string]bool
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{Invalid: map[string]bool{}}
+}
+
+func (p *FakeProvider) Send(ctx context.Context, message Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Invalid[message.Token] {
+		return ErrInvalidToken
+	}
+	p.Sent = append(p.Sent, message)
+	return nil
+}
+
+// Messages returns a copy of the messages sent so far
+func (p *FakeProvider) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.Sent...)
+}
@@ -0,0 +1,266 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/domain/repositories"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	apiKeyPrefix       = "wtk_"
+	apiKeyPrefixLength = 8
+	apiKeySecretLength = 40
+	// Last used is only written when it is older than this, to avoid a database write on every request
+	apiKeyTouchInterval = time.Minute
+)
+
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+type BotUsecase interface {
+	CreateBot(ownerID string, request models.CreateBotRequest) (*entities.UserResponse, error)
+	GetBots(ownerID string) ([]entities.UserResponse, error)
+	CreateAPIKey(ownerID, botID string, request models.CreateAPIKeyRequest) (*models.APIKeyResponse, error)
+	GetAPIKeys(ownerID, botID string) ([]models.APIKeyResponse, error)
+	RevokeAPIKey(ownerID, botID, keyID string) error
+	AuthenticateAPIKey(key string) (*helper.Claims, error)
+}
+
+type botUsecase struct {
+	userRepo       repositories.UserRepository
+	socketPathRepo repositories.SocketPathRepository
+	apiKeyRepo     repositories.APIKeyRepository
+}
+
+func NewBotUsecase(userRepo repositories.UserRepository, socketPathRepo repositories.SocketPathRepository, apiKeyRepo repositories.APIKeyRepository) BotUsecase {
+	return &botUsecase{
+		userRepo:       userRepo,
+		socketPathRepo: socketPathRepo,
+		apiKeyRepo:     apiKeyRepo,
+	}
+}
+
+func (b *botUsecase) CreateBot(ownerID string, request models.CreateBotRequest) (*entities.UserResponse, error) {
+	owner, err := b.userRepo.FindByID(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil || owner.IsBot {
+		return nil, errors.New("only users can create bots")
+	}
+
+	username := strings.TrimSpace(request.Username)
+	existingUser, err := b.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if existingUser != nil {
+		return nil, errors.New("username already exists")
+	}
+
+	// Bots never log in with a password, a random one keeps the column populated
+	password, err := helper.GeneratePassword(32)
+	if err != nil {
+		return nil, errors.New("failed to generate password")
+	}
+	hashedPassword, err := helper.HashPassword(password)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	socketID, err := getAvailableSocketPathID(b.userRepo, b.socketPathRepo)
+	if err != nil {
+		return nil, errors.New("failed to assign socket path: " + err.Error())
+	}
+
+	// Bots have no inbox to verify, they are trusted through their owner
+	now := time.Now()
+	botID := uuid.New().String()
+	bot := &entities.User{
+		ID:              botID,
+		Username:        username,
+		Email:           fmt.Sprintf("%s@bots.wetalk.local", botID),
+		Password:        hashedPassword,
+		DisplayName:     strings.TrimSpace(request.DisplayName),
+		IsBot:           true,
+		OwnerID:         &ownerID,
+		EmailVerifiedAt: &now,
+		SocketID:        socketID,
+	}
+	err = b.userRepo.Create(bot)
+	if err != nil {
+		return nil, errors.New("failed to create bot: " + err.Error())
+	}
+
+	response := mappingUserResponse(*bot)
+	return &response, nil
+}
+
+func (b *botUsecase) GetBots(ownerID string) ([]entities.UserResponse, error) {
+	bots, err := b.userRepo.FindBotsByOwner(ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []entities.UserResponse
+	for _, v := range bots {
+		responses = append(responses, mappingUserResponse(v))
+	}
+	return responses, nil
+}
+
+func (b *botUsecase) CreateAPIKey(ownerID, botID string, request models.CreateAPIKeyRequest) (*models.APIKeyResponse, error) {
+	if err := b.checkBotOwner(ownerID, botID); err != nil {
+		return nil, err
+	}
+
+	prefix, err := helper.GenerateRandomString(apiKeyPrefixLength)
+	if err != nil {
+		return nil, errors.New("failed to generate API key")
+	}
+	secret, err := helper.GenerateRandomString(apiKeySecretLength)
+	if err != nil {
+		return nil, errors.New("failed to generate API key")
+	}
+	key := apiKeyPrefix + prefix + "_" + secret
+
+	apiKey := &entities.APIKey{
+		ID:      uuid.New().String(),
+		BotID:   botID,
+		Name:    strings.TrimSpace(request.Name),
+		Prefix:  apiKeyPrefix + prefix,
+		KeyHash: helper.HashToken(key),
+		Scopes:  request.Scopes,
+		RoomIDs: request.RoomIDs,
+	}
+	if request.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, request.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	err = b.apiKeyRepo.Create(apiKey)
+	if err != nil {
+		return nil, errors.New("failed to create API key: " + err.Error())
+	}
+
+	logging.LogAudit("api_key_created", logrus.Fields{"user_id": ownerID, "bot_id": botID, "api_key_id": apiKey.ID, "scopes": apiKey.Scopes})
+
+	response := mappingAPIKey(*apiKey)
+	response.Key = key
+	return &response, nil
+}
+
+func (b *botUsecase) GetAPIKeys(ownerID, botID string) ([]models.APIKeyResponse, error) {
+	if err := b.checkBotOwner(ownerID, botID); err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := b.apiKeyRepo.FindByBotID(botID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.APIKeyResponse
+	for _, v := range apiKeys {
+		responses = append(responses, mappingAPIKey(v))
+	}
+	return responses, nil
+}
+
+func (b *botUsecase) RevokeAPIKey(ownerID, botID, keyID string) error {
+	if err := b.checkBotOwner(ownerID, botID); err != nil {
+		return err
+	}
+
+	apiKey, err := b.apiKeyRepo.FindByID(keyID)
+	if err != nil {
+		return err
+	}
+	if apiKey == nil || apiKey.BotID != botID {
+		return errors.New("API key not found")
+	}
+
+	err = b.apiKeyRepo.Revoke(keyID)
+	if err != nil {
+		return err
+	}
+
+	logging.LogAudit("api_key_revoked", logrus.Fields{"user_id": ownerID, "bot_id": botID, "api_key_id": keyID})
+	return nil
+}
+
+func (b *botUsecase) AuthenticateAPIKey(key string) (*helper.Claims, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := b.apiKeyRepo.FindByHash(helper.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || apiKey.RevokedAt != nil || !apiKey.Bot.IsBot {
+		return nil, ErrInvalidAPIKey
+	}
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if err := b.apiKeyRepo.TouchLastUsed(apiKey.ID, time.Now()); err != nil {
+			logging.Log.Errorf("Failed to update API key last used for %s: %v", apiKey.ID, err)
+		}
+	}
+
+	return &helper.Claims{
+		UserID:        apiKey.Bot.ID,
+		Email:         apiKey.Bot.Email,
+		Username:      apiKey.Bot.Username,
+		SocketGroupID: apiKey.Bot.SocketPath.Path,
+		APIKeyID:      apiKey.ID,
+		Scopes:        apiKey.Scopes,
+		RoomIDs:       apiKey.RoomIDs,
+	}, nil
+}
+
+// checkBotOwner hides bots of other users behind a not found error
+func (b *botUsecase) checkBotOwner(ownerID, botID string) error {
+	bot, err := b.userRepo.FindByID(botID)
+	if err != nil {
+		return err
+	}
+	if bot == nil || !bot.IsBot || bot.OwnerID == nil || *bot.OwnerID != ownerID {
+		return errors.New("bot not found")
+	}
+	return nil
+}
+
+func mappingAPIKey(apiKey entities.APIKey) models.APIKeyResponse {
+	response := models.APIKeyResponse{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		Scopes:    apiKey.Scopes,
+		RoomIDs:   apiKey.RoomIDs,
+		Revoked:   apiKey.RevokedAt != nil,
+		CreatedAt: apiKey.CreatedAt.Format("2006-01-02 15:04"),
+	}
+	if response.RoomIDs == nil {
+		response.RoomIDs = []string{}
+	}
+	if apiKey.LastUsedAt != nil {
+		response.LastUsedAt = apiKey.LastUsedAt.Format("2006-01-02 15:04")
+	}
+	if apiKey.ExpiresAt != nil {
+		response.ExpiresAt = apiKey.ExpiresAt.Format("2006-01-02 15:04")
+	}
+	return response
+}
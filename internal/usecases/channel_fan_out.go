@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"fmt"
+	"time"
+
+	"chat-be/internal/domain/entities"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Subscribers handled per transaction, progress is saved after each batch
+	channelFanOutBatchSize = 500
+	channelFanOutClaimSize = 10
+	// A claimed fan-out is picked up by another worker if no batch finishes within the lease
+	channelFanOutLease       = time.Minute
+	channelFanOutMaxAttempts = 6
+	channelFanOutRetryDelay  = 30 * time.Second
+)
+
+// ProcessChannelFanOuts writes the receipts of channel posts waiting in the outbox and queues their
+// notifications. A fan-out that fails is retried with exponential backoff from its last finished batch
+func (m *messageUsecase) ProcessChannelFanOuts() error {
+	fanOuts, err := m.fanOutRepo.ClaimDue(channelFanOutClaimSize, channelFanOutLease)
+	if err != nil {
+		return err
+	}
+
+	for i := range fanOuts {
+		fanOut := &fanOuts[i]
+		err := m.processChannelFanOut(fanOut)
+		if err == nil {
+			continue
+		}
+
+		fanOut.Attempts++
+		if fanOut.Attempts >= channelFanOutMaxAttempts {
+			fanOut.Status = entities.ChannelFanOutFailed
+		}
+		fanOut.NextAttemptAt = time.Now().Add(channelFanOutRetryDelay << (fanOut.Attempts - 1))
+		logging.Log.Errorf("Channel fan-out %s attempt %d failed: %v", fanOut.ID, fanOut.Attempts, err)
+		if err := m.fanOutRepo.Reschedule(fanOut); err != nil {
+			logging.Log.Errorf("Failed to reschedule channel fan-out %s: %v", fanOut.ID, err)
+		}
+	}
+	return nil
+}
+
+func (m *messageUsecase) processChannelFanOut(fanOut *entities.ChannelFanOut) error {
+	message, err := m.messageRepo.FindByID(fanOut.MessageID)
+	if err != nil {
+		return err
+	}
+	if message == nil {
+		return fmt.Errorf("message %s not found", fanOut.MessageID)
+	}
+
+	for fanOut.Status == entities.ChannelFanOutPending {
+		userIDs, err := m.chatRoom.FindParticipantIDs(fanOut.ChatRoomID, fanOut.LastUserID, channelFanOutBatchSize)
+		if err != nil {
+			return err
+		}
+		blockers, err := m.blockRepo.FindBlockerIDs(fanOut.SenderID, userIDs)
+		if err != nil {
+			return err
+		}
+
+		var messageStatuses []entities.MessageStatus
+		for _, v := range userIDs {
+			if v == fanOut.SenderID || containsString(blockers, v) {
+				continue
+			}
+			messageStatuses = append(messageStatuses, entities.MessageStatus{
+				ID:         uuid.New().String(),
+				MessageID:  message.ID,
+				ReceiverID: v,
+				Status:     entities.StatusSend,
+			})
+		}
+
+		// Queued before the batch is stored, a crash in between repeats these notifications instead of dropping them
+		for _, v := range messageStatuses {
+			queueNotification(m.notifications, v.ReceiverID, EventMessageNew, message.ChatRoomID, message)
+		}
+
+		progress := *fanOut
+		if len(userIDs) > 0 {
+			progress.LastUserID = userIDs[len(userIDs)-1]
+		}
+		if len(userIDs) < channelFanOutBatchSize {
+			progress.Status = entities.ChannelFanOutDone
+		}
+		err = m.fanOutRepo.SaveBatch(&progress, messageStatuses, channelFanOutLease)
+		if err != nil {
+			return err
+		}
+		*fanOut = progress
+	}
+	return nil
+}
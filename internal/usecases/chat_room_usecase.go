@@ -6,6 +6,8 @@ import (
 	"chat-be/internal/domain/repositories"
 	"chat-be/package/helper"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,18 +16,55 @@ import (
 type ChatRoomUsecase interface {
 	CreateRoom(userCreator string, userIDs []string, isGroup bool, roomName string) (*models.GetChatRoomResponse, error)
 	FindUsersByRoomID(roomID string) ([]entities.ChatRoomParticipant, error)
-	GetRoomsForUser(userID string, page int, limit int) ([]models.GetChatRoomResponse, int64, error)
+	GetRoomsForUser(userID string, filter models.RoomListFilter, page int, limit int) ([]models.GetChatRoomResponse, int64, error)
+	AddMembers(actorID, roomID string, userIDs []string) (*models.GetChatRoomResponse, error)
+	RemoveMember(actorID, roomID, userID string) error
+	ChangeRole(actorID, roomID, userID, role string) error
+	LeaveRoom(userID, roomID string) error
+	UpdateRoom(actorID, roomID string, request models.UpdateRoomRequest) (*models.GetChatRoomResponse, error)
+	CreateInvite(actorID, roomID string, request models.CreateInviteRequest) (*models.InviteResponse, error)
+	GetInvites(actorID, roomID string) ([]models.InviteResponse, error)
+	RevokeInvite(actorID, roomID, inviteID string) error
+	JoinByInvite(userID, token string) (*models.GetChatRoomResponse, error)
+	RequestToJoin(userID, roomID string, request models.CreateJoinRequestRequest) (*models.JoinRequestResponse, error)
+	GetJoinRequests(actorID, roomID string) ([]models.JoinRequestResponse, error)
+	DecideJoinRequest(actorID, roomID, requestID string, approve bool) (*models.JoinRequestResponse, error)
+	CreateChannel(ownerID string, request models.CreateChannelRequest) (*models.GetChatRoomResponse, error)
+	Subscribe(userID, roomID string) (*models.GetChatRoomResponse, error)
+	PinRoom(userID, roomID string, pinned bool) (*models.GetChatRoomResponse, error)
+	ArchiveRoom(userID, roomID string, archived bool) (*models.GetChatRoomResponse, error)
+	PinMessage(userID, roomID, messageID string) (*models.PinnedMessageResponse, error)
+	UnpinMessage(userID, roomID, messageID string) error
+	GetPinnedMessages(userID, roomID string) ([]models.PinnedMessageResponse, error)
 }
 
 type chatRoomUsecase struct {
-	chatRoomRepo repositories.ChatRoomRepository
-	userRepo     repositories.UserRepository
+	chatRoomRepo    repositories.ChatRoomRepository
+	userRepo        repositories.UserRepository
+	messageRepo     repositories.MessageRepository
+	inviteRepo      repositories.RoomInviteRepository
+	joinRequestRepo repositories.JoinRequestRepository
+	pinRepo         repositories.PinnedMessageRepository
+	contactRepo     repositories.ContactRepository
+	blockRepo       repositories.BlockRepository
+	publisher       EventPublisher
+	webhooks        WebhookDispatcher
+	notifications   NotificationPublisher
 }
 
-func NewChatRoomUsecase(chatRoomRepo repositories.ChatRoomRepository, userRepo repositories.UserRepository) ChatRoomUsecase {
+func NewChatRoomUsecase(chatRoomRepo repositories.ChatRoomRepository, userRepo repositories.UserRepository, messageRepo repositories.MessageRepository, inviteRepo repositories.RoomInviteRepository, joinRequestRepo repositories.JoinRequestRepository, pinRepo repositories.PinnedMessageRepository, contactRepo repositories.ContactRepository, blockRepo repositories.BlockRepository, publisher EventPublisher, webhooks WebhookDispatcher, notifications NotificationPublisher) ChatRoomUsecase {
 	return &chatRoomUsecase{
-		chatRoomRepo: chatRoomRepo,
-		userRepo:     userRepo,
+		chatRoomRepo:    chatRoomRepo,
+		userRepo:        userRepo,
+		messageRepo:     messageRepo,
+		inviteRepo:      inviteRepo,
+		joinRequestRepo: joinRequestRepo,
+		pinRepo:         pinRepo,
+		contactRepo:     contactRepo,
+		blockRepo:       blockRepo,
+		publisher:       publisher,
+		webhooks:        webhooks,
+		notifications:   notifications,
 	}
 }
 
@@ -40,10 +79,17 @@ func (u *chatRoomUsecase) CreateRoom(userIdCreator string, userIDs []string, isG
 		if err != nil || user == nil {
 			return nil, errors.New("invalid sender")
 		}
+		if user.ID == userIdCreator {
+			if err := requireVerifiedEmail(user, EmailVerificationMessaging); err != nil {
+				return nil, err
+			}
+		}
 		users = append(users, *user)
 		participant := models.Participants{
 			UserID:     user.ID,
 			SocketPath: user.SocketPath.Path,
+			Online:     isOnline(*user),
+			LastSeenAt: formatLastSeen(*user),
 		}
 		participantList = append(participantList, participant)
 	}
@@ -53,6 +99,13 @@ func (u *chatRoomUsecase) CreateRoom(userIdCreator string, userIDs []string, isG
 		for _, v := range users {
 			if v.ID != userIdCreator {
 				roomName = v.Username
+				blocked, err := u.blockRepo.IsBlockedEither(userIdCreator, v.ID)
+				if err != nil {
+					return nil, err
+				}
+				if blocked {
+					return nil, ErrUserBlocked
+				}
 			}
 		}
 
@@ -68,19 +121,38 @@ func (u *chatRoomUsecase) CreateRoom(userIdCreator string, userIDs []string, isG
 			chatRoom.Name = roomName
 			return &chatRoom, nil
 		}
+
+		for _, v := range users {
+			if v.ID == userIdCreator || !v.ContactsOnly {
+				continue
+			}
+			ok, err := isContact(u.contactRepo, userIdCreator, v.ID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, ErrContactRequired
+			}
+		}
 	}
 
 	room := &entities.ChatRoom{
-		ID:      uuid.New().String(),
-		Name:    roomName,
-		IsGroup: len(userIDs) > 2,
+		ID:        uuid.New().String(),
+		Name:      roomName,
+		IsGroup:   isGroup || len(userIDs) > 2,
+		CreatedBy: &userIdCreator,
 	}
 
 	var participants []entities.ChatRoomParticipant
 	for _, userID := range userIDs {
+		role := entities.RoleMember
+		if room.IsGroup && userID == userIdCreator {
+			role = entities.RoleOwner
+		}
 		participants = append(participants, entities.ChatRoomParticipant{
 			ID:         uuid.New().String(),
 			UserID:     userID,
+			Role:       role,
 			JoinedAt:   time.Now(),
 			ChatRoomID: room.ID,
 		})
@@ -91,7 +163,11 @@ func (u *chatRoomUsecase) CreateRoom(userIdCreator string, userIDs []string, isG
 		return nil, err
 	}
 
+	room.Participants = participants
 	chatRoom := mappingChatRoom(*room, userIdCreator)
+	for i := range participantList {
+		participantList[i].Role = participants[i].Role
+	}
 	chatRoom.Participants = participantList
 	chatRoom.Name = roomName
 	return &chatRoom, nil
@@ -101,10 +177,16 @@ func (u *chatRoomUsecase) FindUsersByRoomID(roomID string) ([]entities.ChatRoomP
 	return u.chatRoomRepo.FindUsersByRoomID(roomID)
 }
 
-func (u *chatRoomUsecase) GetRoomsForUser(userID string, page int, limit int) ([]models.GetChatRoomResponse, int64, error) {
+func (u *chatRoomUsecase) GetRoomsForUser(userID string, filter models.RoomListFilter, page int, limit int) ([]models.GetChatRoomResponse, int64, error) {
 	offset := (page - 1) * limit
 
-	rooms, total, err := u.chatRoomRepo.FindRoomsByUser(userID, offset, limit)
+	rooms, total, err := u.chatRoomRepo.FindRoomsByUser(userID, repositories.RoomFilter{
+		Archived:    filter.Archived,
+		Name:        strings.TrimSpace(filter.Name),
+		Participant: strings.TrimPrefix(strings.TrimSpace(filter.Participant), "@"),
+		Type:        filter.Type,
+		UnreadOnly:  filter.UnreadOnly,
+	}, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -118,6 +200,81 @@ func (u *chatRoomUsecase) GetRoomsForUser(userID string, page int, limit int) ([
 	return chatRooms, total, nil
 }
 
+// UpdateRoom changes the name, description or avatar of a group, only its owner and admins may do so
+func (u *chatRoomUsecase) UpdateRoom(actorID, roomID string, request models.UpdateRoomRequest) (*models.GetChatRoomResponse, error) {
+	room, actor, err := u.findGroupMember(roomID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanManage() {
+		return nil, ErrRoomPermission
+	}
+
+	var changes []string
+	if request.Name != nil {
+		name := strings.TrimSpace(*request.Name)
+		if name == "" {
+			return nil, errors.New("room name cannot be empty")
+		}
+		if name != room.Name {
+			room.Name = name
+			changes = append(changes, fmt.Sprintf("changed the group name to \"%s\"", name))
+		}
+	}
+	if request.Description != nil {
+		description := strings.TrimSpace(*request.Description)
+		if description != room.Description {
+			room.Description = description
+			changes = append(changes, "updated the group description")
+		}
+	}
+	if request.Avatar != nil && *request.Avatar != room.Avatar {
+		room.Avatar = *request.Avatar
+		changes = append(changes, "updated the group photo")
+	}
+	if request.IsPrivate != nil && *request.IsPrivate != room.IsPrivate {
+		room.IsPrivate = *request.IsPrivate
+		if room.IsPrivate {
+			changes = append(changes, "made the group private, new members need approval")
+		} else {
+			changes = append(changes, "made the group open to anyone with an invite link")
+		}
+	}
+
+	if len(changes) > 0 {
+		err = u.chatRoomRepo.UpdateRoomDetails(room)
+		if err != nil {
+			return nil, errors.New("failed to update room: " + err.Error())
+		}
+
+		u.recordSystemMessage(room, actorID, displayName(actor.User)+" "+strings.Join(changes, ", "))
+
+		var recipients []models.Participants
+		for _, v := range room.Participants {
+			recipients = append(recipients, models.Participants{
+				UserID:     v.UserID,
+				SocketPath: v.User.SocketPath.Path,
+			})
+		}
+		publishEvent(u.publisher, Event{
+			Type:       EventRoomUpdated,
+			RoomID:     room.ID,
+			Recipients: recipients,
+			Data: models.RoomUpdatedData{
+				RoomID:      room.ID,
+				Name:        room.Name,
+				Description: room.Description,
+				Avatar:      room.Avatar,
+				IsPrivate:   room.IsPrivate,
+				UpdatedBy:   actorID,
+			},
+		})
+	}
+
+	chatRoom := mappingChatRoom(*room, actorID)
+	return &chatRoom, nil
+}
+
 func mappingChatRoom(room entities.ChatRoom, userID string) models.GetChatRoomResponse {
 	var chatRoom models.GetChatRoomResponse
 	chatRoom.ID = room.ID
@@ -129,6 +286,15 @@ func mappingChatRoom(room entities.ChatRoom, userID string) models.GetChatRoomRe
 	}
 
 	chatRoom.Name = name
+	chatRoom.IsGroup = room.IsGroup
+	chatRoom.IsPrivate = room.IsPrivate
+	chatRoom.IsChannel = room.IsChannel
+	// Groups have their own name, a direct room is shown as the other participant
+	if room.IsGroup {
+		chatRoom.Name = room.Name
+		chatRoom.Description = room.Description
+		chatRoom.Avatar = room.Avatar
+	}
 	if room.LastMessageID != nil {
 		chatRoom.LastMessage = room.Message.Content
 		chatRoom.LastMessageTime = helper.FormatMessageTime(room.Message.CreatedAt)
@@ -138,6 +304,17 @@ func mappingChatRoom(room entities.ChatRoom, userID string) models.GetChatRoomRe
 		participant := models.Participants{
 			UserID:     v.User.ID,
 			SocketPath: v.User.SocketPath.Path,
+			Online:     isOnline(v.User),
+			LastSeenAt: formatLastSeen(v.User),
+			Role:       v.Role,
+		}
+		if v.UserID == userID {
+			chatRoom.Role = v.Role
+			chatRoom.Pinned = v.PinnedAt != nil
+			chatRoom.Archived = v.ArchivedAt != nil
+			if v.MutedUntil != nil && v.MutedUntil.After(time.Now()) {
+				chatRoom.MutedUntil = v.MutedUntil.Format("2006-01-02 15:04")
+			}
 		}
 		chatRoom.Participants = append(chatRoom.Participants, participant)
 	}
@@ -0,0 +1,238 @@
+package usecases
+
+import (
+	"errors"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/domain/repositories"
+
+	"github.com/google/uuid"
+)
+
+// Contact events, sent to the user on the other side of the request
+const (
+	EventContactRequested = "contact.requested"
+	EventContactAccepted  = "contact.accepted"
+)
+
+// Direction of a friend request as seen by the user listing it
+const (
+	FriendRequestIncoming = "incoming"
+	FriendRequestOutgoing = "outgoing"
+)
+
+var (
+	ErrFriendRequestNotFound = errors.New("friend request not found")
+	ErrContactRequired       = errors.New("this user only accepts direct messages from contacts")
+)
+
+type ContactUsecase interface {
+	SendRequest(userID, targetID string) (*models.FriendRequestResponse, error)
+	GetRequests(userID string) ([]models.FriendRequestResponse, error)
+	RespondRequest(userID, requestID string, accept bool) (*models.FriendRequestResponse, error)
+	GetContacts(userID string) ([]models.ContactResponse, error)
+	RemoveContact(userID, contactID string) error
+}
+
+type contactUsecase struct {
+	contactRepo   repositories.ContactRepository
+	userRepo      repositories.UserRepository
+	blockRepo     repositories.BlockRepository
+	publisher     EventPublisher
+	notifications NotificationPublisher
+}
+
+func NewContactUsecase(contactRepo repositories.ContactRepository, userRepo repositories.UserRepository, blockRepo repositories.BlockRepository, publisher EventPublisher, notifications NotificationPublisher) ContactUsecase {
+	return &contactUsecase{
+		contactRepo:   contactRepo,
+		userRepo:      userRepo,
+		blockRepo:     blockRepo,
+		publisher:     publisher,
+		notifications: notifications,
+	}
+}
+
+// SendRequest asks targetID to become a contact, a pending request the other way round is accepted instead
+func (c *contactUsecase) SendRequest(userID, targetID string) (*models.FriendRequestResponse, error) {
+	if userID == targetID {
+		return nil, errors.New("you cannot add yourself as a contact")
+	}
+	target, err := c.userRepo.FindByID(targetID)
+	if err != nil || target == nil {
+		return nil, errors.New("user not found")
+	}
+	blocked, err := c.blockRepo.IsBlockedEither(userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, errors.New("you cannot send a friend request to this user")
+	}
+
+	contact, err := c.contactRepo.FindBetween(userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case contact == nil:
+		contact = &entities.Contact{
+			ID:          uuid.New().String(),
+			RequesterID: userID,
+			AddresseeID: targetID,
+			Status:      entities.ContactPending,
+		}
+		if err := c.contactRepo.Create(contact); err != nil {
+			return nil, errors.New("failed to send friend request: " + err.Error())
+		}
+	case contact.Status == entities.ContactAccepted:
+		return nil, errors.New("you are already contacts")
+	case contact.Status == entities.ContactPending && contact.RequesterID == userID:
+		// Sending again is a no-op, the request is already waiting
+		contact.Addressee = *target
+		response := mappingFriendRequest(*contact, userID)
+		return &response, nil
+	case contact.Status == entities.ContactPending:
+		return c.RespondRequest(userID, contact.ID, true)
+	default:
+		if err := c.contactRepo.Reopen(contact.ID, userID, targetID); err != nil {
+			return nil, errors.New("failed to send friend request: " + err.Error())
+		}
+	}
+
+	contact, err = c.contactRepo.FindByID(contact.ID)
+	if err != nil || contact == nil {
+		return nil, ErrFriendRequestNotFound
+	}
+
+	c.notifyContact(contact.Addressee, EventContactRequested, mappingFriendRequest(*contact, targetID))
+	response := mappingFriendRequest(*contact, userID)
+	return &response, nil
+}
+
+func (c *contactUsecase) GetRequests(userID string) ([]models.FriendRequestResponse, error) {
+	contacts, err := c.contactRepo.FindPending(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.FriendRequestResponse
+	for _, v := range contacts {
+		responses = append(responses, mappingFriendRequest(v, userID))
+	}
+	return responses, nil
+}
+
+// RespondRequest accepts or declines a request sent to userID and tells the sender when it was accepted
+func (c *contactUsecase) RespondRequest(userID, requestID string, accept bool) (*models.FriendRequestResponse, error) {
+	contact, err := c.contactRepo.FindByID(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if contact == nil || contact.AddresseeID != userID {
+		return nil, ErrFriendRequestNotFound
+	}
+
+	status := entities.ContactDeclined
+	if accept {
+		status = entities.ContactAccepted
+	}
+	ok, err := c.contactRepo.Respond(requestID, status)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("friend request was already answered")
+	}
+
+	now := time.Now()
+	contact.Status = status
+	contact.RespondedAt = &now
+
+	// A declined sender is not told, so declining does not invite a new request
+	if accept {
+		c.notifyContact(contact.Requester, EventContactAccepted, mappingFriendRequest(*contact, contact.RequesterID))
+	}
+
+	response := mappingFriendRequest(*contact, userID)
+	return &response, nil
+}
+
+func (c *contactUsecase) GetContacts(userID string) ([]models.ContactResponse, error) {
+	contacts, err := c.contactRepo.FindAccepted(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.ContactResponse
+	for _, v := range contacts {
+		other := v.Other(userID)
+		response := models.ContactResponse{
+			UserID:      other.ID,
+			Username:    other.Username,
+			DisplayName: other.DisplayName,
+			Avatar:      other.Avatar,
+			Online:      isOnline(other),
+			LastSeenAt:  formatLastSeen(other),
+		}
+		if v.RespondedAt != nil {
+			response.Since = v.RespondedAt.Format("2006-01-02 15:04")
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// RemoveContact removes a contact or cancels a friend request between the two users
+func (c *contactUsecase) RemoveContact(userID, contactID string) error {
+	contact, err := c.contactRepo.FindBetween(userID, contactID)
+	if err != nil {
+		return err
+	}
+	if contact == nil || contact.Status == entities.ContactDeclined {
+		return ErrFriendRequestNotFound
+	}
+	return c.contactRepo.Delete(contact.ID)
+}
+
+func (c *contactUsecase) notifyContact(user entities.User, eventType string, data models.FriendRequestResponse) {
+	publishEvent(c.publisher, Event{
+		Type: eventType,
+		Recipients: []models.Participants{{
+			UserID:     user.ID,
+			SocketPath: user.SocketPath.Path,
+		}},
+		Data: data,
+	})
+	queueNotification(c.notifications, user.ID, eventType, "", data)
+}
+
+// isContact reports whether the two users accepted each other as contacts
+func isContact(contactRepo repositories.ContactRepository, userID, otherID string) (bool, error) {
+	contact, err := contactRepo.FindBetween(userID, otherID)
+	if err != nil {
+		return false, err
+	}
+	return contact != nil && contact.Status == entities.ContactAccepted, nil
+}
+
+// mappingFriendRequest describes the request from the point of view of userID
+func mappingFriendRequest(contact entities.Contact, userID string) models.FriendRequestResponse {
+	other := contact.Other(userID)
+	direction := FriendRequestIncoming
+	if contact.RequesterID == userID {
+		direction = FriendRequestOutgoing
+	}
+	return models.FriendRequestResponse{
+		ID:          contact.ID,
+		UserID:      other.ID,
+		Username:    other.Username,
+		DisplayName: other.DisplayName,
+		Avatar:      other.Avatar,
+		Direction:   direction,
+		Status:      contact.Status,
+		CreatedAt:   contact.CreatedAt.Format("2006-01-02 15:04"),
+	}
+}
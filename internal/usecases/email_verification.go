@@ -0,0 +1,32 @@
+package usecases
+
+import (
+	"chat-be/internal/config"
+	"chat-be/internal/domain/entities"
+	"errors"
+)
+
+// Values accepted by the EMAIL_VERIFICATION_REQUIRED setting
+const (
+	EmailVerificationOff       = "off"
+	EmailVerificationLogin     = "login"
+	EmailVerificationMessaging = "messaging"
+)
+
+var errEmailNotVerified = errors.New("email address has not been verified")
+
+// requireVerifiedEmail returns an error when the configured verification mode
+// covers the given action and the user has not verified their email yet.
+// Bots are exempt, their placeholder address can never be verified
+func requireVerifiedEmail(user *entities.User, action string) error {
+	mode := config.GetEnv("EMAIL_VERIFICATION_REQUIRED", EmailVerificationOff)
+	if mode == EmailVerificationOff || user.EmailVerifiedAt != nil || user.IsBot {
+		return nil
+	}
+
+	// Blocking login also blocks messaging, since no token can be issued
+	if mode == action || (mode == EmailVerificationLogin && action == EmailVerificationMessaging) {
+		return errEmailNotVerified
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package usecases
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"chat-be/package/logging"
+)
+
+// Event types pushed to connected clients through the WebSocket gateway
+const (
+	EventMessageNew      = "message.new"
+	EventRoomUpdated     = "room.updated"
+	EventMessagePinned   = "message.pinned"
+	EventMessageUnpinned = "message.unpinned"
+)
+
+// Event is a real-time notification the WebSocket gateway forwards to the recipients' connections
+type Event struct {
+	Type       string                `json:"type"`
+	RoomID     string                `json:"room_id,omitempty"`
+	Recipients []models.Participants `json:"recipients"`
+	Data       interface{}           `json:"data"`
+}
+
+type EventPublisher interface {
+	Publish(event Event) error
+}
+
+// publishEvent delivers an event on a best effort basis, the change it describes is already stored
+func publishEvent(publisher EventPublisher, event Event) {
+	if publisher == nil || len(event.Recipients) == 0 {
+		return
+	}
+	if err := publisher.Publish(event); err != nil {
+		logging.Log.Errorf("Failed to publish %s event: %v", event.Type, err)
+	}
+}
@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"chat-be/package/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrInvalidCredentials is returned for every failed login so callers cannot tell whether an email is registered
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrLoginLocked        = errors.New("too many failed login attempts, please try again later")
+)
+
+type loginThrottlePolicy struct {
+	// Failures allowed before the key starts getting locked
+	threshold int
+	// Lock duration after reaching the threshold, doubled for every further failure
+	baseLockout time.Duration
+	maxLockout  time.Duration
+}
+
+var (
+	accountLoginPolicy = loginThrottlePolicy{threshold: 5, baseLockout: 30 * time.Second, maxLockout: 15 * time.Minute}
+	ipLoginPolicy      = loginThrottlePolicy{threshold: 20, baseLockout: 30 * time.Second, maxLockout: 15 * time.Minute}
+)
+
+// Failures older than this no longer count towards a lockout
+const loginAttemptWindow = time.Hour
+
+func accountLoginKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+func twoFactorLoginKey(userID string) string {
+	return "2fa:" + userID
+}
+
+// checkLoginLocked returns ErrLoginLocked when either the account or the client IP is temporarily locked
+func (u *userUsecase) checkLoginLocked(email, ip string) error {
+	keys := []string{accountLoginKey(email)}
+	if ip != "" {
+		keys = append(keys, ipLoginKey(ip))
+	}
+
+	for _, key := range keys {
+		if err := u.checkKeyLocked(key); err != nil {
+			if errors.Is(err, ErrLoginLocked) {
+				logging.LogAudit("login_blocked", logrus.Fields{"email": email, "ip": ip, "key": key})
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *userUsecase) checkKeyLocked(key string) error {
+	attempt, err := u.loginAttemptRepo.FindByKey(key)
+	if err != nil {
+		return err
+	}
+	if attempt != nil && attempt.LockedUntil != nil && time.Now().Before(*attempt.LockedUntil) {
+		return ErrLoginLocked
+	}
+	return nil
+}
+
+func (u *userUsecase) recordFailedLogin(email, ip string) {
+	logging.LogAudit("login_failed", logrus.Fields{"email": email, "ip": ip})
+
+	u.registerFailure(accountLoginKey(email), accountLoginPolicy)
+	if ip != "" {
+		u.registerFailure(ipLoginKey(ip), ipLoginPolicy)
+	}
+}
+
+func (u *userUsecase) registerFailure(key string, policy loginThrottlePolicy) {
+	now := time.Now()
+	attempt, err := u.loginAttemptRepo.IncrementFailure(key, now, loginAttemptWindow)
+	if err != nil {
+		logging.Log.Errorf("Failed to record login attempt for %s: %v", key, err)
+		return
+	}
+	if attempt.FailedCount < policy.threshold {
+		return
+	}
+
+	lockedUntil := now.Add(policy.lockout(attempt.FailedCount))
+	if err := u.loginAttemptRepo.LockUntil(key, lockedUntil); err != nil {
+		logging.Log.Errorf("Failed to lock login attempts for %s: %v", key, err)
+		return
+	}
+	logging.LogAudit("login_locked", logrus.Fields{"key": key, "failed_count": attempt.FailedCount, "locked_until": lockedUntil})
+}
+
+// lockout doubles the base lockout for every failure past the threshold, capped at maxLockout
+func (p loginThrottlePolicy) lockout(failedCount int) time.Duration {
+	lockout := p.baseLockout << (failedCount - p.threshold)
+	if lockout <= 0 || lockout > p.maxLockout {
+		return p.maxLockout
+	}
+	return lockout
+}
+
+// clearFailedLogins resets the account counter only, so one valid login cannot unlock an IP that is guessing other accounts
+func (u *userUsecase) clearFailedLogins(email string) {
+	if err := u.loginAttemptRepo.Delete(accountLoginKey(email)); err != nil {
+		logging.Log.Errorf("Failed to clear login attempts for %s: %v", email, err)
+	}
+}
@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"encoding/base64"
+	"errors"
+	"html"
+	"strings"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/repositories"
+
+	"github.com/google/uuid"
+)
+
+const maxSearchLimit = 50
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SearchMessages finds messages matching text in the rooms the user belongs to, newest first.
+// The returned cursor fetches the next page and is empty on the last one.
+func (m *messageUsecase) SearchMessages(userID, text, roomID, cursor string, limit int) ([]models.MessageSearchResponse, string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, "", errors.New("search query is required")
+	}
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	query := repositories.MessageSearchQuery{
+		Text:   text,
+		RoomID: roomID,
+		Limit:  limit + 1,
+	}
+	if cursor != "" {
+		before, id, err := decodeSearchCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query.BeforeTime = before
+		query.BeforeID = id
+	}
+
+	results, err := m.messageRepo.SearchMessages(userID, query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(results) > limit {
+		results = results[:limit]
+		last := results[len(results)-1]
+		nextCursor = encodeSearchCursor(last.CreatedAt, last.ID)
+	}
+
+	var responses []models.MessageSearchResponse
+	for _, v := range results {
+		responses = append(responses, models.MessageSearchResponse{
+			ID:       v.ID,
+			RoomID:   v.ChatRoomID,
+			SenderID: v.SenderID,
+			Text:     v.Content,
+			Snippet:  escapeSnippet(v.Snippet),
+			Time:     v.CreatedAt.Format("2006-01-02 15:04"),
+		})
+	}
+
+	return responses, nextCursor, nil
+}
+
+func encodeSearchCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+func decodeSearchCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	// The ID is compared with a uuid column, anything else would fail in the database instead
+	if _, err := uuid.Parse(parts[1]); err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	return createdAt, parts[1], nil
+}
+
+// escapeSnippet escapes the message text so clients can render the snippet as HTML, keeping only the highlight tags
+func escapeSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, "&lt;mark&gt;", "<mark>")
+	return strings.ReplaceAll(escaped, "&lt;/mark&gt;", "</mark>")
+}
@@ -16,19 +16,32 @@ type MessageUsecase interface {
 	GetMessageHistory(senderID, roomId string, limit, page int) ([]models.Message, int64, error)
 	SaveMessage(message *entities.Message) error
 	UpdateStatusMessage(messageID, receiverID string, status int) error
+	SendMessage(senderID, roomID, content string) (*models.Message, error)
+	SearchMessages(userID, text, roomID, cursor string, limit int) ([]models.MessageSearchResponse, string, error)
+	ProcessChannelFanOuts() error
 }
 
 type messageUsecase struct {
-	chatRoom    repositories.ChatRoomRepository
-	messageRepo repositories.MessageRepository
-	userRepo    repositories.UserRepository
+	chatRoom      repositories.ChatRoomRepository
+	messageRepo   repositories.MessageRepository
+	userRepo      repositories.UserRepository
+	publisher     EventPublisher
+	webhooks      WebhookDispatcher
+	notifications NotificationPublisher
+	blockRepo     repositories.BlockRepository
+	fanOutRepo    repositories.ChannelFanOutRepository
 }
 
-func NewMessageUsecase(chatRoom repositories.ChatRoomRepository, messageRepo repositories.MessageRepository, userRepo repositories.UserRepository) MessageUsecase {
+func NewMessageUsecase(chatRoom repositories.ChatRoomRepository, messageRepo repositories.MessageRepository, userRepo repositories.UserRepository, blockRepo repositories.BlockRepository, fanOutRepo repositories.ChannelFanOutRepository, publisher EventPublisher, webhooks WebhookDispatcher, notifications NotificationPublisher) MessageUsecase {
 	return &messageUsecase{
-		chatRoom:    chatRoom,
-		messageRepo: messageRepo,
-		userRepo:    userRepo,
+		chatRoom:      chatRoom,
+		messageRepo:   messageRepo,
+		userRepo:      userRepo,
+		publisher:     publisher,
+		webhooks:      webhooks,
+		notifications: notifications,
+		blockRepo:     blockRepo,
+		fanOutRepo:    fanOutRepo,
 	}
 }
 
@@ -51,7 +64,7 @@ func (m *messageUsecase) GetMessageHistory(senderID, roomId string, limit, page
 
 	offset := (page - 1) * limit
 
-	messageHistories, total, err := m.messageRepo.GetMessagesByRoomID(roomId, offset, limit)
+	messageHistories, total, err := m.messageRepo.GetMessagesByRoomID(roomId, senderID, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -64,7 +77,9 @@ func (m *messageUsecase) GetMessageHistory(senderID, roomId string, limit, page
 			message.Status = v.MessageStatus[0].Status
 		}
 
-		if v.SenderID == senderID {
+		if v.Type == entities.MessageTypeSystem {
+			message.Type = entities.MessageTypeSystem
+		} else if v.SenderID == senderID {
 			message.Type = "outgoing"
 		} else {
 			message.Type = "incoming"
@@ -82,41 +97,201 @@ func (m *messageUsecase) SaveMessage(message *entities.Message) error {
 	if err != nil || sender == nil {
 		return errors.New("invalid sender")
 	}
+	if err := requireVerifiedEmail(sender, EmailVerificationMessaging); err != nil {
+		return err
+	}
 
-	receiver, err := m.chatRoom.FindRoomByID(message.ChatRoomID)
+	// Channels can have thousands of subscribers, so the room is loaded without them
+	receiver, err := m.chatRoom.FindRoom(message.ChatRoomID)
 	if err != nil || receiver == nil {
 		return errors.New("invalid receiver")
 	}
+	if !receiver.IsChannel {
+		receiver.Participants, err = m.chatRoom.FindUsersByRoomID(receiver.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Removed and departed members keep the room ID, so membership is checked on every post
+	member, err := m.findMember(receiver, message.SenderID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return errors.New("invalid sender")
+	}
+	if receiver.IsChannel && !member.CanManage() {
+		return ErrChannelReadOnly
+	}
+	if !receiver.IsGroup {
+		for _, v := range receiver.Participants {
+			if v.UserID == message.SenderID {
+				continue
+			}
+			blocked, err := m.blockRepo.IsBlockedEither(message.SenderID, v.UserID)
+			if err != nil {
+				return err
+			}
+			if blocked {
+				return ErrUserBlocked
+			}
+		}
+	}
 	loc, err := time.LoadLocation("Asia/Jakarta")
 	if err != nil {
 		fmt.Println("Error loading location:", err)
 		return err
 	}
 	message.CreatedAt = time.Now().In(loc)
+	// Only the backend records system messages, never trust the type sent by clients
+	message.Type = entities.MessageTypeText
 	fmt.Println("message.CreatedAt :", message.CreatedAt)
-	err = m.messageRepo.SaveMessage(message)
+
+	if receiver.IsChannel {
+		// The subscriber receipts are written by ProcessChannelFanOuts, stored in the same transaction as the post
+		err = m.fanOutRepo.CreateWithMessage(message, &entities.ChannelFanOut{
+			ID:            uuid.New().String(),
+			MessageID:     message.ID,
+			ChatRoomID:    message.ChatRoomID,
+			SenderID:      message.SenderID,
+			Status:        entities.ChannelFanOutPending,
+			NextAttemptAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		err = m.messageRepo.SaveMessage(message)
+		if err != nil {
+			return err
+		}
+		if err := m.fanOut(message, receiver.Participants); err != nil {
+			return err
+		}
+	}
+
+	dispatchWebhook(m.webhooks, message.ChatRoomID, entities.WebhookEventMessageNew, models.WebhookMessageData{
+		ID:        message.ID,
+		SenderID:  message.SenderID,
+		Content:   message.Content,
+		CreatedAt: message.CreatedAt.UTC().Format(time.RFC3339),
+	})
+
+	return nil
+}
+
+// findMember looks the sender up in the loaded participants, or in the database for a channel
+func (m *messageUsecase) findMember(room *entities.ChatRoom, userID string) (*entities.ChatRoomParticipant, error) {
+	if room.IsChannel {
+		return m.chatRoom.FindParticipant(room.ID, userID)
+	}
+	return findParticipant(room, userID), nil
+}
+
+// fanOut creates the delivery receipts of a message and queues it for the other participants,
+// skipping those who blocked the sender
+func (m *messageUsecase) fanOut(message *entities.Message, participants []entities.ChatRoomParticipant) error {
+	blockers, err := m.findBlockers(message.SenderID, participants)
 	if err != nil {
 		return err
 	}
 
-	for _, v := range receiver.Participants {
-		if v.UserID != message.SenderID {
-			messageStatus := entities.MessageStatus{
-				ID:         uuid.New().String(),
-				MessageID:  message.ID,
-				ReceiverID: v.UserID,
-				Status:     entities.StatusSend,
-			}
-			err = m.messageRepo.CreateMessageStatus(&messageStatus)
-			if err != nil {
-				return err
-			}
+	var messageStatuses []entities.MessageStatus
+	for _, v := range participants {
+		if v.UserID == message.SenderID || containsString(blockers, v.UserID) {
+			continue
 		}
+		messageStatuses = append(messageStatuses, entities.MessageStatus{
+			ID:         uuid.New().String(),
+			MessageID:  message.ID,
+			ReceiverID: v.UserID,
+			Status:     entities.StatusSend,
+		})
+	}
+	err = m.messageRepo.CreateMessageStatuses(messageStatuses)
+	if err != nil {
+		return err
 	}
 
+	for _, v := range messageStatuses {
+		queueNotification(m.notifications, v.ReceiverID, EventMessageNew, message.ChatRoomID, message)
+	}
 	return nil
 }
 
+// findBlockers returns the participants who blocked senderID
+func (m *messageUsecase) findBlockers(senderID string, participants []entities.ChatRoomParticipant) ([]string, error) {
+	var userIDs []string
+	for _, v := range participants {
+		if v.UserID != senderID {
+			userIDs = append(userIDs, v.UserID)
+		}
+	}
+	return m.blockRepo.FindBlockerIDs(senderID, userIDs)
+}
+
 func (m *messageUsecase) UpdateStatusMessage(messageID, receiverID string, status int) error {
 	return m.messageRepo.UpdateMessageStatus(messageID, receiverID, status)
 }
+
+// SendMessage stores a message sent over HTTP, used by bots that have no WebSocket connection,
+// and pushes it to the other participants through the gateway
+func (m *messageUsecase) SendMessage(senderID, roomID, content string) (*models.Message, error) {
+	room, err := m.chatRoom.FindRoomByID(roomID)
+	if err != nil || room == nil {
+		return nil, errors.New("invalid receiver")
+	}
+
+	blockers, err := m.findBlockers(senderID, room.Participants)
+	if err != nil {
+		return nil, err
+	}
+
+	validSender := false
+	var recipients []models.Participants
+	for _, v := range room.Participants {
+		if v.UserID == senderID {
+			validSender = true
+			continue
+		}
+		if containsString(blockers, v.UserID) {
+			continue
+		}
+		recipients = append(recipients, models.Participants{
+			UserID:     v.UserID,
+			SocketPath: v.User.SocketPath.Path,
+		})
+	}
+	if !validSender {
+		return nil, errors.New("invalid sender")
+	}
+
+	message := &entities.Message{
+		ID:         uuid.New().String(),
+		ChatRoomID: roomID,
+		SenderID:   senderID,
+		Content:    content,
+		Status:     entities.StatusSend,
+	}
+	err = m.SaveMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	publishEvent(m.publisher, Event{
+		Type:       EventMessageNew,
+		RoomID:     roomID,
+		Recipients: recipients,
+		Data:       message,
+	})
+
+	return &models.Message{
+		ID:     message.ID,
+		RoomID: message.ChatRoomID,
+		Type:   "outgoing",
+		Text:   message.Content,
+		Time:   message.CreatedAt.Format("2006-01-02 15:04"),
+		Status: message.Status,
+	}, nil
+}
@@ -0,0 +1,191 @@
+package usecases
+
+import (
+	"errors"
+	"strings"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/domain/repositories"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrUserBlocked = errors.New("you cannot message this user")
+
+type ModerationUsecase interface {
+	BlockUser(userID, targetID string) error
+	UnblockUser(userID, targetID string) error
+	GetBlockedUsers(userID string) ([]models.BlockedUserResponse, error)
+	ReportUser(userID string, request models.CreateReportRequest) (*models.ReportResponse, error)
+}
+
+type moderationUsecase struct {
+	blockRepo    repositories.BlockRepository
+	reportRepo   repositories.ReportRepository
+	contactRepo  repositories.ContactRepository
+	userRepo     repositories.UserRepository
+	messageRepo  repositories.MessageRepository
+	chatRoomRepo repositories.ChatRoomRepository
+}
+
+func NewModerationUsecase(blockRepo repositories.BlockRepository, reportRepo repositories.ReportRepository, contactRepo repositories.ContactRepository, userRepo repositories.UserRepository, messageRepo repositories.MessageRepository, chatRoomRepo repositories.ChatRoomRepository) ModerationUsecase {
+	return &moderationUsecase{
+		blockRepo:    blockRepo,
+		reportRepo:   reportRepo,
+		contactRepo:  contactRepo,
+		userRepo:     userRepo,
+		messageRepo:  messageRepo,
+		chatRoomRepo: chatRoomRepo,
+	}
+}
+
+// BlockUser stops targetID from starting direct rooms with or messaging the user and ends any contact between them
+func (m *moderationUsecase) BlockUser(userID, targetID string) error {
+	if userID == targetID {
+		return errors.New("you cannot block yourself")
+	}
+	target, err := m.userRepo.FindByID(targetID)
+	if err != nil || target == nil {
+		return errors.New("user not found")
+	}
+
+	err = m.blockRepo.Create(&entities.UserBlock{
+		ID:        uuid.New().String(),
+		BlockerID: userID,
+		BlockedID: targetID,
+	})
+	if err != nil {
+		return errors.New("failed to block user: " + err.Error())
+	}
+
+	contact, err := m.contactRepo.FindBetween(userID, targetID)
+	if err != nil {
+		return err
+	}
+	if contact != nil {
+		return m.contactRepo.Delete(contact.ID)
+	}
+	return nil
+}
+
+func (m *moderationUsecase) UnblockUser(userID, targetID string) error {
+	ok, err := m.blockRepo.Delete(userID, targetID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("user is not blocked")
+	}
+	return nil
+}
+
+func (m *moderationUsecase) GetBlockedUsers(userID string) ([]models.BlockedUserResponse, error) {
+	blocks, err := m.blockRepo.FindByBlocker(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.BlockedUserResponse
+	for _, v := range blocks {
+		responses = append(responses, models.BlockedUserResponse{
+			UserID:      v.BlockedID,
+			Username:    v.Blocked.Username,
+			DisplayName: v.Blocked.DisplayName,
+			Avatar:      v.Blocked.Avatar,
+			BlockedAt:   v.CreatedAt.Format("2006-01-02 15:04"),
+		})
+	}
+	return responses, nil
+}
+
+// ReportUser opens a moderation case, reported messages are copied as evidence and must have been
+// written by the reported user in a room the reporter belongs to
+func (m *moderationUsecase) ReportUser(userID string, request models.CreateReportRequest) (*models.ReportResponse, error) {
+	if userID == request.UserID {
+		return nil, errors.New("you cannot report yourself")
+	}
+	reported, err := m.userRepo.FindByID(request.UserID)
+	if err != nil || reported == nil {
+		return nil, errors.New("user not found")
+	}
+
+	report := &entities.Report{
+		ID:             uuid.New().String(),
+		ReporterID:     userID,
+		ReportedUserID: reported.ID,
+		Reason:         request.Reason,
+		Details:        strings.TrimSpace(request.Details),
+		Status:         entities.ReportOpen,
+	}
+	if request.RoomID != "" {
+		participant, err := m.chatRoomRepo.FindParticipant(request.RoomID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if participant == nil {
+			return nil, ErrRoomNotFound
+		}
+		report.ChatRoomID = &request.RoomID
+	}
+
+	for _, messageID := range request.MessageIDs {
+		if containsEvidence(report.Evidence, messageID) {
+			continue
+		}
+		message, err := m.messageRepo.FindByID(messageID)
+		if err != nil {
+			return nil, err
+		}
+		if message == nil || message.SenderID != reported.ID || (request.RoomID != "" && message.ChatRoomID != request.RoomID) {
+			return nil, errors.New("message not found")
+		}
+		participant, err := m.chatRoomRepo.FindParticipant(message.ChatRoomID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if participant == nil {
+			return nil, errors.New("message not found")
+		}
+
+		report.Evidence = append(report.Evidence, entities.ReportEvidence{
+			ID:         uuid.New().String(),
+			ReportID:   report.ID,
+			MessageID:  message.ID,
+			ChatRoomID: message.ChatRoomID,
+			SenderID:   message.SenderID,
+			Content:    message.Content,
+			SentAt:     message.CreatedAt,
+		})
+	}
+
+	err = m.reportRepo.Create(report)
+	if err != nil {
+		return nil, errors.New("failed to create report: " + err.Error())
+	}
+	logging.LogAudit("user_reported", logrus.Fields{"user_id": userID, "reported_user_id": reported.ID, "report_id": report.ID, "reason": report.Reason, "evidence": len(report.Evidence)})
+
+	response := &models.ReportResponse{
+		ID:             report.ID,
+		ReportedUserID: report.ReportedUserID,
+		Reason:         report.Reason,
+		Status:         report.Status,
+		EvidenceCount:  len(report.Evidence),
+		CreatedAt:      report.CreatedAt.Format("2006-01-02 15:04"),
+	}
+	if report.ChatRoomID != nil {
+		response.RoomID = *report.ChatRoomID
+	}
+	return response, nil
+}
+
+func containsEvidence(evidence []entities.ReportEvidence, messageID string) bool {
+	for _, v := range evidence {
+		if v.MessageID == messageID {
+			return true
+		}
+	}
+	return false
+}
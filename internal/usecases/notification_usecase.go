@@ -0,0 +1,141 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"chat-be/internal/cache"
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/repositories"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+)
+
+// NotificationPublisher hands notifications to the notification service through the unread-notifications topic
+type NotificationPublisher interface {
+	PublishNotification(notification models.Notification) error
+}
+
+// NotificationUsecase stores notifications for offline users and replays them when they reconnect
+type NotificationUsecase interface {
+	Notify(notification models.Notification) error
+	FlushPending(userID string) error
+}
+
+type notificationUsecase struct {
+	userRepo  repositories.UserRepository
+	store     cache.NotificationStore
+	publisher EventPublisher
+	pusher    PushUsecase
+}
+
+func NewNotificationUsecase(userRepo repositories.UserRepository, store cache.NotificationStore, publisher EventPublisher, pusher PushUsecase) NotificationUsecase {
+	return &notificationUsecase{
+		userRepo:  userRepo,
+		store:     store,
+		publisher: publisher,
+		pusher:    pusher,
+	}
+}
+
+func (n *notificationUsecase) Notify(notification models.Notification) error {
+	user, err := n.userRepo.FindByID(notification.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	// Online users already got the event live from the gateway
+	if isOnline(*user) {
+		return nil
+	}
+
+	err = n.store.Push(context.Background(), notification)
+	if err != nil {
+		return err
+	}
+
+	// The app may be closed, reach the user's devices as well
+	if n.pusher != nil {
+		if err := n.pusher.SendPush(notification); err != nil {
+			logging.Log.Errorf("Failed to push %s notification to %s: %v", notification.Type, notification.UserID, err)
+		}
+	}
+	return nil
+}
+
+func (n *notificationUsecase) FlushPending(userID string) error {
+	ctx := context.Background()
+	notifications, err := n.store.Flush(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	user, err := n.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		// Keep them for the next reconnect rather than losing them
+		return n.restore(ctx, notifications, errors.New("user not found"))
+	}
+	if n.publisher == nil {
+		return n.restore(ctx, notifications, errors.New("event publisher is not configured"))
+	}
+
+	recipients := []models.Participants{{
+		UserID:     user.ID,
+		SocketPath: user.SocketPath.Path,
+	}}
+	for i, v := range notifications {
+		err := n.publisher.Publish(Event{
+			Type:       v.Type,
+			RoomID:     v.RoomID,
+			Recipients: recipients,
+			Data:       v.Data,
+		})
+		if err != nil {
+			return n.restore(ctx, notifications[i:], err)
+		}
+	}
+	return nil
+}
+
+func (n *notificationUsecase) restore(ctx context.Context, notifications []models.Notification, cause error) error {
+	for _, v := range notifications {
+		if err := n.store.Push(ctx, v); err != nil {
+			logging.Log.Errorf("Failed to restore notification %s: %v", v.ID, err)
+		}
+	}
+	return cause
+}
+
+// queueNotification is best effort like publishEvent, the event it describes is already stored
+func queueNotification(publisher NotificationPublisher, userID, eventType, roomID string, data interface{}) {
+	if publisher == nil {
+		return
+	}
+
+	value, err := json.Marshal(data)
+	if err != nil {
+		logging.Log.Errorf("Failed to encode %s notification: %v", eventType, err)
+		return
+	}
+
+	err = publisher.PublishNotification(models.Notification{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Type:      eventType,
+		RoomID:    roomID,
+		Data:      value,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		logging.Log.Errorf("Failed to queue %s notification for %s: %v", eventType, userID, err)
+	}
+}
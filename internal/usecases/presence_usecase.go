@@ -0,0 +1,185 @@
+package usecases
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/domain/repositories"
+	"chat-be/package/logging"
+)
+
+// Presence statuses reported by the WebSocket gateway
+const (
+	PresenceConnect    = "connect"
+	PresenceHeartbeat  = "heartbeat"
+	PresenceDisconnect = "disconnect"
+)
+
+const EventPresenceUpdated = "presence.updated"
+
+// A user whose gateway stopped sending heartbeats for this long is considered offline
+const presenceTimeout = 90 * time.Second
+
+type PresenceUsecase interface {
+	HandlePresence(event models.PresenceEvent) error
+	ExpireStale() error
+}
+
+type presenceUsecase struct {
+	userRepo  repositories.UserRepository
+	publisher EventPublisher
+
+	mu sync.Mutex
+	// Open connections per user with their last heartbeat, so closing one device keeps the user online
+	connections map[string]map[string]time.Time
+}
+
+func NewPresenceUsecase(userRepo repositories.UserRepository, publisher EventPublisher) PresenceUsecase {
+	return &presenceUsecase{
+		userRepo:    userRepo,
+		publisher:   publisher,
+		connections: map[string]map[string]time.Time{},
+	}
+}
+
+func (p *presenceUsecase) HandlePresence(event models.PresenceEvent) error {
+	user, err := p.userRepo.FindByID(event.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	now := time.Now()
+	var online bool
+	switch event.Status {
+	case PresenceConnect, PresenceHeartbeat:
+		p.trackConnection(user.ID, event.ConnectionID, now)
+		online = true
+	case PresenceDisconnect:
+		online = p.dropConnection(user.ID, event.ConnectionID, now)
+	default:
+		return errors.New("unknown presence status: " + event.Status)
+	}
+
+	err = p.userRepo.UpdatePresence(user.ID, online, now)
+	if err != nil {
+		return err
+	}
+
+	// Heartbeats only refresh last seen, contacts are told when the user goes online or offline
+	if online != isOnline(*user) {
+		user.Online = online
+		user.LastSeenAt = &now
+		p.broadcast(*user)
+	}
+	return nil
+}
+
+// ExpireStale marks users offline when their gateway disappeared without sending a disconnect
+func (p *presenceUsecase) ExpireStale() error {
+	now := time.Now()
+	cutoff := now.Add(-presenceTimeout)
+
+	p.mu.Lock()
+	for userID, connections := range p.connections {
+		for connectionID, lastSeen := range connections {
+			if lastSeen.Before(cutoff) {
+				delete(connections, connectionID)
+			}
+		}
+		if len(connections) == 0 {
+			delete(p.connections, userID)
+		}
+	}
+	p.mu.Unlock()
+
+	users, err := p.userRepo.FindStaleOnline(cutoff)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range users {
+		if err := p.userRepo.UpdatePresence(v.ID, false, *lastSeenOr(v, now)); err != nil {
+			return err
+		}
+		v.Online = false
+		p.broadcast(v)
+	}
+	return nil
+}
+
+func (p *presenceUsecase) trackConnection(userID, connectionID string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.connections[userID] == nil {
+		p.connections[userID] = map[string]time.Time{}
+	}
+	p.connections[userID][connectionID] = now
+}
+
+// dropConnection forgets a connection and reports whether the user still has another live one
+func (p *presenceUsecase) dropConnection(userID, connectionID string, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	connections := p.connections[userID]
+	delete(connections, connectionID)
+	for _, lastSeen := range connections {
+		if now.Sub(lastSeen) < presenceTimeout {
+			return true
+		}
+	}
+	delete(p.connections, userID)
+	return false
+}
+
+func (p *presenceUsecase) broadcast(user entities.User) {
+	contacts, err := p.userRepo.FindDirectContacts(user.ID)
+	if err != nil {
+		logging.Log.Errorf("Failed to load contacts of %s: %v", user.ID, err)
+		return
+	}
+
+	var recipients []models.Participants
+	for _, v := range contacts {
+		recipients = append(recipients, models.Participants{
+			UserID:     v.ID,
+			SocketPath: v.SocketPath.Path,
+		})
+	}
+
+	publishEvent(p.publisher, Event{
+		Type:       EventPresenceUpdated,
+		Recipients: recipients,
+		Data: models.PresenceResponse{
+			UserID:     user.ID,
+			Online:     user.Online,
+			LastSeenAt: formatLastSeen(user),
+		},
+	})
+}
+
+// isOnline trusts the stored flag only while heartbeats keep last seen fresh
+func isOnline(user entities.User) bool {
+	return user.Online && user.LastSeenAt != nil && time.Since(*user.LastSeenAt) < presenceTimeout
+}
+
+func formatLastSeen(user entities.User) string {
+	if user.LastSeenAt == nil {
+		return ""
+	}
+	return user.LastSeenAt.Format("2006-01-02 15:04")
+}
+
+func lastSeenOr(user entities.User, fallback time.Time) *time.Time {
+	if user.LastSeenAt != nil {
+		return user.LastSeenAt
+	}
+	return &fallback
+}
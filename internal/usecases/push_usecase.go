@@ -0,0 +1,238 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/domain/repositories"
+	"chat-be/internal/push"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+)
+
+const (
+	pushBodyMaxLength = 200
+	pushGenericTitle  = "WeTalk"
+	pushGenericBody   = "New message"
+)
+
+type PushUsecase interface {
+	RegisterDevice(userID, sessionID string, request models.RegisterDeviceRequest) (*models.DeviceResponse, error)
+	GetDevices(userID string) ([]models.DeviceResponse, error)
+	RemoveDevice(userID, deviceID string) error
+	GetPushSettings(userID string) (*models.PushSettingsResponse, error)
+	UpdatePushSettings(userID string, request models.PushSettingsRequest) (*models.PushSettingsResponse, error)
+	MuteRoom(userID, roomID string, request models.MuteRoomRequest) (*models.MuteRoomResponse, error)
+	SendPush(notification models.Notification) error
+}
+
+type pushUsecase struct {
+	deviceTokenRepo repositories.DeviceTokenRepository
+	userRepo        repositories.UserRepository
+	chatRoomRepo    repositories.ChatRoomRepository
+	providers       map[string]push.Provider // Keyed by device platform
+}
+
+func NewPushUsecase(deviceTokenRepo repositories.DeviceTokenRepository, userRepo repositories.UserRepository, chatRoomRepo repositories.ChatRoomRepository, providers map[string]push.Provider) PushUsecase {
+	return &pushUsecase{
+		deviceTokenRepo: deviceTokenRepo,
+		userRepo:        userRepo,
+		chatRoomRepo:    chatRoomRepo,
+		providers:       providers,
+	}
+}
+
+// RegisterDevice binds the token to the session it was registered from, so signing that session out stops its pushes
+func (p *pushUsecase) RegisterDevice(userID, sessionID string, request models.RegisterDeviceRequest) (*models.DeviceResponse, error) {
+	deviceToken := &entities.DeviceToken{
+		ID:       uuid.New().String(),
+		UserID:   userID,
+		Token:    strings.TrimSpace(request.Token),
+		Platform: request.Platform,
+	}
+	if sessionID != "" {
+		deviceToken.SessionID = &sessionID
+	}
+	err := p.deviceTokenRepo.Save(deviceToken)
+	if err != nil {
+		return nil, errors.New("failed to register device: " + err.Error())
+	}
+
+	response := mappingDevice(*deviceToken)
+	return &response, nil
+}
+
+func (p *pushUsecase) GetDevices(userID string) ([]models.DeviceResponse, error) {
+	deviceTokens, err := p.deviceTokenRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.DeviceResponse
+	for _, v := range deviceTokens {
+		responses = append(responses, mappingDevice(v))
+	}
+	return responses, nil
+}
+
+func (p *pushUsecase) RemoveDevice(userID, deviceID string) error {
+	deviceToken, err := p.deviceTokenRepo.FindByID(deviceID)
+	if err != nil {
+		return err
+	}
+	if deviceToken == nil || deviceToken.UserID != userID {
+		return errors.New("device not found")
+	}
+	return p.deviceTokenRepo.Delete(deviceID)
+}
+
+func (p *pushUsecase) GetPushSettings(userID string) (*models.PushSettingsResponse, error) {
+	user, err := p.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+	return &models.PushSettingsResponse{Preview: user.PushPreview}, nil
+}
+
+func (p *pushUsecase) UpdatePushSettings(userID string, request models.PushSettingsRequest) (*models.PushSettingsResponse, error) {
+	user, err := p.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	user.PushPreview = request.Preview
+	err = p.userRepo.Update(user)
+	if err != nil {
+		return nil, errors.New("failed to update push settings: " + err.Error())
+	}
+	return &models.PushSettingsResponse{Preview: user.PushPreview}, nil
+}
+
+func (p *pushUsecase) MuteRoom(userID, roomID string, request models.MuteRoomRequest) (*models.MuteRoomResponse, error) {
+	participant, err := p.chatRoomRepo.FindParticipant(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if participant == nil {
+		return nil, errors.New("room not found")
+	}
+
+	var mutedUntil *time.Time
+	if request.Muted {
+		until := time.Now().AddDate(100, 0, 0)
+		if request.DurationMinutes > 0 {
+			until = time.Now().Add(time.Duration(request.DurationMinutes) * time.Minute)
+		}
+		mutedUntil = &until
+	}
+
+	err = p.chatRoomRepo.SetMutedUntil(roomID, userID, mutedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.MuteRoomResponse{RoomID: roomID}
+	if mutedUntil != nil {
+		response.MutedUntil = mutedUntil.Format("2006-01-02 15:04")
+	}
+	return response, nil
+}
+
+// SendPush notifies the devices of an offline user about a new message, respecting room mutes and preview privacy
+func (p *pushUsecase) SendPush(notification models.Notification) error {
+	if notification.Type != EventMessageNew || len(p.providers) == 0 {
+		return nil
+	}
+
+	var message entities.Message
+	if err := json.Unmarshal(notification.Data, &message); err != nil {
+		return err
+	}
+
+	participant, err := p.chatRoomRepo.FindParticipant(notification.RoomID, notification.UserID)
+	if err != nil {
+		return err
+	}
+	if participant == nil || (participant.MutedUntil != nil && participant.MutedUntil.After(time.Now())) {
+		return nil
+	}
+
+	deviceTokens, err := p.deviceTokenRepo.FindByUserID(notification.UserID)
+	if err != nil || len(deviceTokens) == 0 {
+		return err
+	}
+
+	user, err := p.userRepo.FindByID(notification.UserID)
+	if err != nil || user == nil {
+		return errors.New("user not found")
+	}
+	sender, err := p.userRepo.FindByID(message.SenderID)
+	if err != nil || sender == nil {
+		return errors.New("invalid sender")
+	}
+
+	pushMessage := buildPushMessage(user.PushPreview, *sender, message)
+	ctx := context.Background()
+	for _, v := range deviceTokens {
+		provider, ok := p.providers[v.Platform]
+		if !ok {
+			continue
+		}
+
+		pushMessage.Token = v.Token
+		err := provider.Send(ctx, pushMessage)
+		if errors.Is(err, push.ErrInvalidToken) {
+			// The app was uninstalled or the token rotated, stop sending to it
+			if err := p.deviceTokenRepo.DeleteByToken(v.Token); err != nil {
+				logging.Log.Errorf("Failed to remove invalid device token %s: %v", v.ID, err)
+			}
+			continue
+		}
+		if err != nil {
+			logging.Log.Errorf("Failed to send push to device %s: %v", v.ID, err)
+		}
+	}
+	return nil
+}
+
+func buildPushMessage(preview string, sender entities.User, message entities.Message) push.Message {
+	pushMessage := push.Message{
+		Title: pushGenericTitle,
+		Body:  pushGenericBody,
+		Data: map[string]string{
+			"type":    EventMessageNew,
+			"room_id": message.ChatRoomID,
+		},
+	}
+
+	senderName := displayName(sender)
+
+	switch preview {
+	case entities.PushPreviewNone:
+	case entities.PushPreviewSender:
+		pushMessage.Title = senderName
+	default:
+		pushMessage.Title = senderName
+		pushMessage.Body = message.Content
+		if runes := []rune(message.Content); len(runes) > pushBodyMaxLength {
+			pushMessage.Body = string(runes[:pushBodyMaxLength]) + "…"
+		}
+		pushMessage.Data["message_id"] = message.ID
+	}
+	return pushMessage
+}
+
+func mappingDevice(deviceToken entities.DeviceToken) models.DeviceResponse {
+	return models.DeviceResponse{
+		ID:        deviceToken.ID,
+		Platform:  deviceToken.Platform,
+		CreatedAt: deviceToken.CreatedAt.Format("2006-01-02 15:04"),
+		UpdatedAt: deviceToken.UpdatedAt.Format("2006-01-02 15:04"),
+	}
+}
@@ -0,0 +1,98 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+var ErrChannelReadOnly = errors.New("only channel admins can post in this channel")
+
+// CreateChannel creates a broadcast room owned by the caller, subscribers join later through Subscribe or invite links
+func (u *chatRoomUsecase) CreateChannel(ownerID string, request models.CreateChannelRequest) (*models.GetChatRoomResponse, error) {
+	owner, err := u.userRepo.FindByID(ownerID)
+	if err != nil || owner == nil {
+		return nil, errors.New("user not found")
+	}
+	if err := requireVerifiedEmail(owner, EmailVerificationMessaging); err != nil {
+		return nil, err
+	}
+
+	room := &entities.ChatRoom{
+		ID:          uuid.New().String(),
+		Name:        strings.TrimSpace(request.Name),
+		Description: strings.TrimSpace(request.Description),
+		Avatar:      request.Avatar,
+		IsGroup:     true,
+		IsChannel:   true,
+		IsPrivate:   request.IsPrivate,
+		CreatedBy:   &ownerID,
+	}
+	participants := []entities.ChatRoomParticipant{{
+		ID:         uuid.New().String(),
+		ChatRoomID: room.ID,
+		UserID:     ownerID,
+		Role:       entities.RoleOwner,
+		JoinedAt:   time.Now(),
+	}}
+
+	err = u.chatRoomRepo.CreateRoom(room, participants)
+	if err != nil {
+		return nil, err
+	}
+
+	participants[0].User = *owner
+	room.Participants = participants
+	chatRoom := mappingChatRoom(*room, ownerID)
+	return &chatRoom, nil
+}
+
+// Subscribe adds the caller to a public channel, private channels take a join request instead
+func (u *chatRoomUsecase) Subscribe(userID, roomID string) (*models.GetChatRoomResponse, error) {
+	room, err := u.chatRoomRepo.FindRoomByID(roomID)
+	if err != nil || room == nil || !room.IsChannel {
+		return nil, ErrRoomNotFound
+	}
+	if findParticipant(room, userID) != nil {
+		chatRoom := mappingChatRoom(*room, userID)
+		return &chatRoom, nil
+	}
+	if room.IsPrivate {
+		return nil, errors.New("this channel is private, send a join request instead")
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	err = u.chatRoomRepo.AddParticipants([]entities.ChatRoomParticipant{{
+		ID:         uuid.New().String(),
+		ChatRoomID: roomID,
+		UserID:     userID,
+		Role:       entities.RoleMember,
+		JoinedAt:   time.Now(),
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	room, err = u.chatRoomRepo.FindRoomByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	u.recordMembershipChange(room, userID, fmt.Sprintf("%s subscribed", displayName(*user)), models.WebhookMembershipData{
+		Action:  MembershipJoined,
+		ActorID: userID,
+		UserIDs: []string{userID},
+	})
+
+	chatRoom := mappingChatRoom(*room, userID)
+	return &chatRoom, nil
+}
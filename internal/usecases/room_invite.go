@@ -0,0 +1,194 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const inviteTokenLength = 24
+
+var ErrInvalidInvite = errors.New("invite link is invalid or has expired")
+
+func (u *chatRoomUsecase) CreateInvite(actorID, roomID string, request models.CreateInviteRequest) (*models.InviteResponse, error) {
+	_, actor, err := u.findGroupMember(roomID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanManage() {
+		return nil, ErrRoomPermission
+	}
+
+	token, err := helper.GenerateRandomString(inviteTokenLength)
+	if err != nil {
+		return nil, errors.New("failed to generate invite token")
+	}
+
+	invite := &entities.RoomInvite{
+		ID:         uuid.New().String(),
+		ChatRoomID: roomID,
+		Token:      token,
+		CreatedBy:  actorID,
+		MaxUses:    request.MaxUses,
+	}
+	if request.ExpiresInHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(request.ExpiresInHours) * time.Hour)
+		invite.ExpiresAt = &expiresAt
+	}
+
+	err = u.inviteRepo.Create(invite)
+	if err != nil {
+		return nil, errors.New("failed to create invite: " + err.Error())
+	}
+
+	logging.LogAudit("room_invite_created", logrus.Fields{"user_id": actorID, "room_id": roomID, "invite_id": invite.ID})
+
+	response := mappingInvite(*invite)
+	return &response, nil
+}
+
+func (u *chatRoomUsecase) GetInvites(actorID, roomID string) ([]models.InviteResponse, error) {
+	_, actor, err := u.findGroupMember(roomID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanManage() {
+		return nil, ErrRoomPermission
+	}
+
+	invites, err := u.inviteRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.InviteResponse
+	for _, v := range invites {
+		responses = append(responses, mappingInvite(v))
+	}
+	return responses, nil
+}
+
+func (u *chatRoomUsecase) RevokeInvite(actorID, roomID, inviteID string) error {
+	_, actor, err := u.findGroupMember(roomID, actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.CanManage() {
+		return ErrRoomPermission
+	}
+
+	invite, err := u.inviteRepo.FindByID(inviteID)
+	if err != nil {
+		return err
+	}
+	if invite == nil || invite.ChatRoomID != roomID {
+		return errors.New("invite not found")
+	}
+
+	err = u.inviteRepo.Revoke(inviteID)
+	if err != nil {
+		return err
+	}
+
+	logging.LogAudit("room_invite_revoked", logrus.Fields{"user_id": actorID, "room_id": roomID, "invite_id": inviteID})
+	return nil
+}
+
+// JoinByInvite adds the user to the invite's group, joining a group you are already in just returns it
+func (u *chatRoomUsecase) JoinByInvite(userID, token string) (*models.GetChatRoomResponse, error) {
+	invite, err := u.inviteRepo.FindByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil {
+		return nil, ErrInvalidInvite
+	}
+
+	room, err := u.chatRoomRepo.FindRoomByID(invite.ChatRoomID)
+	if err != nil || room == nil {
+		return nil, ErrInvalidInvite
+	}
+	if findParticipant(room, userID) != nil {
+		chatRoom := mappingChatRoom(*room, userID)
+		return &chatRoom, nil
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	// A private group only lets the link holder ask to join, without using up the invite
+	if room.IsPrivate {
+		if !inviteUsable(*invite) {
+			return nil, ErrInvalidInvite
+		}
+		if _, err := u.createJoinRequest(room, userID, ""); err != nil {
+			return nil, err
+		}
+		return nil, ErrJoinRequestPending
+	}
+
+	ok, err := u.inviteRepo.Use(invite.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidInvite
+	}
+
+	err = u.chatRoomRepo.AddParticipants([]entities.ChatRoomParticipant{{
+		ID:         uuid.New().String(),
+		ChatRoomID: room.ID,
+		UserID:     userID,
+		Role:       entities.RoleMember,
+		JoinedAt:   time.Now(),
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	room, err = u.chatRoomRepo.FindRoomByID(room.ID)
+	if err != nil {
+		return nil, err
+	}
+	u.recordMembershipChange(room, userID, fmt.Sprintf("%s joined using an invite link", displayName(*user)), models.WebhookMembershipData{
+		Action:  MembershipJoined,
+		ActorID: userID,
+		UserIDs: []string{userID},
+	})
+
+	chatRoom := mappingChatRoom(*room, userID)
+	return &chatRoom, nil
+}
+
+func inviteUsable(invite entities.RoomInvite) bool {
+	if invite.RevokedAt != nil || (invite.ExpiresAt != nil && invite.ExpiresAt.Before(time.Now())) {
+		return false
+	}
+	return invite.MaxUses == 0 || invite.Uses < invite.MaxUses
+}
+
+func mappingInvite(invite entities.RoomInvite) models.InviteResponse {
+	response := models.InviteResponse{
+		ID:        invite.ID,
+		RoomID:    invite.ChatRoomID,
+		Token:     invite.Token,
+		MaxUses:   invite.MaxUses,
+		Uses:      invite.Uses,
+		Revoked:   invite.RevokedAt != nil,
+		CreatedAt: invite.CreatedAt.Format("2006-01-02 15:04"),
+	}
+	if invite.ExpiresAt != nil {
+		response.ExpiresAt = invite.ExpiresAt.Format("2006-01-02 15:04")
+	}
+	return response
+}
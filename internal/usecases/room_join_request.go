@@ -0,0 +1,206 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+// Join request events, sent to the room admins and to the requester
+const (
+	EventJoinRequested      = "room.join_requested"
+	EventJoinRequestDecided = "room.join_request_decided"
+)
+
+var (
+	// ErrJoinRequestPending tells an invite link user that the private group needs an admin to let them in
+	ErrJoinRequestPending = errors.New("join request sent, waiting for an admin to approve it")
+	ErrJoinNotRequired    = errors.New("this room does not accept join requests")
+)
+
+// RequestToJoin asks the admins of a private group to let the user in, repeated requests return the pending one
+func (u *chatRoomUsecase) RequestToJoin(userID, roomID string, request models.CreateJoinRequestRequest) (*models.JoinRequestResponse, error) {
+	room, err := u.chatRoomRepo.FindRoomByID(roomID)
+	if err != nil || room == nil {
+		return nil, ErrRoomNotFound
+	}
+	if !room.IsGroup || !room.IsPrivate {
+		return nil, ErrJoinNotRequired
+	}
+	if findParticipant(room, userID) != nil {
+		return nil, errors.New("you are already a member of this room")
+	}
+
+	return u.createJoinRequest(room, userID, strings.TrimSpace(request.Message))
+}
+
+func (u *chatRoomUsecase) GetJoinRequests(actorID, roomID string) ([]models.JoinRequestResponse, error) {
+	_, actor, err := u.findGroupMember(roomID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanManage() {
+		return nil, ErrRoomPermission
+	}
+
+	joinRequests, err := u.joinRequestRepo.FindPendingByRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.JoinRequestResponse
+	for _, v := range joinRequests {
+		responses = append(responses, mappingJoinRequest(v))
+	}
+	return responses, nil
+}
+
+// DecideJoinRequest approves or rejects a pending request and tells the requester about the outcome
+func (u *chatRoomUsecase) DecideJoinRequest(actorID, roomID, requestID string, approve bool) (*models.JoinRequestResponse, error) {
+	room, actor, err := u.findGroupMember(roomID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanManage() {
+		return nil, ErrRoomPermission
+	}
+
+	joinRequest, err := u.joinRequestRepo.FindByID(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if joinRequest == nil || joinRequest.ChatRoomID != roomID {
+		return nil, errors.New("join request not found")
+	}
+
+	status := entities.JoinRequestRejected
+	if approve {
+		status = entities.JoinRequestApproved
+	}
+	ok, err := u.joinRequestRepo.Decide(requestID, status, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("join request was already decided")
+	}
+
+	now := time.Now()
+	joinRequest.Status = status
+	joinRequest.DecidedBy = &actorID
+	joinRequest.DecidedAt = &now
+
+	if approve && findParticipant(room, joinRequest.UserID) == nil {
+		err = u.chatRoomRepo.AddParticipants([]entities.ChatRoomParticipant{{
+			ID:         uuid.New().String(),
+			ChatRoomID: roomID,
+			UserID:     joinRequest.UserID,
+			Role:       entities.RoleMember,
+			JoinedAt:   now,
+		}})
+		if err != nil {
+			return nil, err
+		}
+
+		room, err = u.chatRoomRepo.FindRoomByID(roomID)
+		if err != nil {
+			return nil, err
+		}
+		u.recordMembershipChange(room, actorID, fmt.Sprintf("%s approved %s's request to join", displayName(actor.User), displayName(joinRequest.User)), models.WebhookMembershipData{
+			Action:  MembershipJoined,
+			ActorID: actorID,
+			UserIDs: []string{joinRequest.UserID},
+		})
+	}
+
+	response := mappingJoinRequest(*joinRequest)
+	publishEvent(u.publisher, Event{
+		Type:   EventJoinRequestDecided,
+		RoomID: roomID,
+		Recipients: []models.Participants{{
+			UserID:     joinRequest.UserID,
+			SocketPath: joinRequest.User.SocketPath.Path,
+		}},
+		Data: response,
+	})
+	queueNotification(u.notifications, joinRequest.UserID, EventJoinRequestDecided, roomID, response)
+
+	return &response, nil
+}
+
+func (u *chatRoomUsecase) createJoinRequest(room *entities.ChatRoom, userID, message string) (*models.JoinRequestResponse, error) {
+	existing, err := u.joinRequestRepo.FindPending(room.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		response := mappingJoinRequest(*existing)
+		return &response, nil
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	joinRequest := &entities.JoinRequest{
+		ID:         uuid.New().String(),
+		ChatRoomID: room.ID,
+		UserID:     userID,
+		Message:    message,
+		Status:     entities.JoinRequestPending,
+	}
+	err = u.joinRequestRepo.Create(joinRequest)
+	if err != nil {
+		return nil, errors.New("failed to create join request: " + err.Error())
+	}
+	joinRequest.User = *user
+
+	response := mappingJoinRequest(*joinRequest)
+
+	// Let every admin know, those offline get it when they reconnect
+	var admins []models.Participants
+	for _, v := range room.Participants {
+		if !v.CanManage() {
+			continue
+		}
+		admins = append(admins, models.Participants{
+			UserID:     v.UserID,
+			SocketPath: v.User.SocketPath.Path,
+		})
+		queueNotification(u.notifications, v.UserID, EventJoinRequested, room.ID, response)
+	}
+	publishEvent(u.publisher, Event{
+		Type:       EventJoinRequested,
+		RoomID:     room.ID,
+		Recipients: admins,
+		Data:       response,
+	})
+
+	return &response, nil
+}
+
+func mappingJoinRequest(joinRequest entities.JoinRequest) models.JoinRequestResponse {
+	response := models.JoinRequestResponse{
+		ID:        joinRequest.ID,
+		RoomID:    joinRequest.ChatRoomID,
+		UserID:    joinRequest.UserID,
+		Username:  joinRequest.User.Username,
+		Message:   joinRequest.Message,
+		Status:    joinRequest.Status,
+		CreatedAt: joinRequest.CreatedAt.Format("2006-01-02 15:04"),
+	}
+	if joinRequest.DecidedBy != nil {
+		response.DecidedBy = *joinRequest.DecidedBy
+	}
+	if joinRequest.DecidedAt != nil {
+		response.DecidedAt = joinRequest.DecidedAt.Format("2006-01-02 15:04")
+	}
+	return response
+}
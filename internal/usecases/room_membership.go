@@ -0,0 +1,280 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+)
+
+// Actions reported in membership.changed webhooks
+const (
+	MembershipAdded       = "added"
+	MembershipJoined      = "joined"
+	MembershipRemoved     = "removed"
+	MembershipLeft        = "left"
+	MembershipRoleChanged = "role_changed"
+)
+
+var (
+	ErrRoomNotFound    = errors.New("room not found")
+	ErrNotGroupRoom    = errors.New("members can only be managed in group rooms")
+	ErrRoomPermission  = errors.New("you do not have permission to manage this room")
+	ErrMemberNotFound  = errors.New("user is not a member of this room")
+	ErrOwnerProtection = errors.New("the room owner cannot be removed or demoted")
+)
+
+func (u *chatRoomUsecase) AddMembers(actorID, roomID string, userIDs []string) (*models.GetChatRoomResponse, error) {
+	room, actor, err := u.findGroupMember(roomID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanManage() {
+		return nil, ErrRoomPermission
+	}
+
+	var participants []entities.ChatRoomParticipant
+	var names []string
+	var addedIDs []string
+	for _, v := range userIDs {
+		if findParticipant(room, v) != nil || containsString(addedIDs, v) {
+			continue
+		}
+		user, err := u.userRepo.FindByID(v)
+		if err != nil || user == nil {
+			return nil, errors.New("user not found")
+		}
+		participants = append(participants, entities.ChatRoomParticipant{
+			ID:         uuid.New().String(),
+			ChatRoomID: roomID,
+			UserID:     user.ID,
+			Role:       entities.RoleMember,
+			JoinedAt:   time.Now(),
+		})
+		names = append(names, displayName(*user))
+		addedIDs = append(addedIDs, user.ID)
+	}
+	if len(participants) == 0 {
+		return nil, errors.New("all users are already members of this room")
+	}
+
+	err = u.chatRoomRepo.AddParticipants(participants)
+	if err != nil {
+		return nil, err
+	}
+
+	room, err = u.chatRoomRepo.FindRoomByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	u.recordMembershipChange(room, actorID, fmt.Sprintf("%s added %s", displayName(actor.User), strings.Join(names, ", ")), models.WebhookMembershipData{
+		Action:  MembershipAdded,
+		ActorID: actorID,
+		UserIDs: addedIDs,
+	})
+
+	chatRoom := mappingChatRoom(*room, actorID)
+	return &chatRoom, nil
+}
+
+func (u *chatRoomUsecase) RemoveMember(actorID, roomID, userID string) error {
+	if actorID == userID {
+		return u.LeaveRoom(userID, roomID)
+	}
+
+	room, actor, err := u.findGroupMember(roomID, actorID)
+	if err != nil {
+		return err
+	}
+	target := findParticipant(room, userID)
+	if target == nil {
+		return ErrMemberNotFound
+	}
+	if !actor.CanManage() {
+		return ErrRoomPermission
+	}
+	if target.Role == entities.RoleOwner {
+		return ErrOwnerProtection
+	}
+	// Admins can only be removed by the owner
+	if target.Role == entities.RoleAdmin && actor.Role != entities.RoleOwner {
+		return ErrRoomPermission
+	}
+
+	err = u.chatRoomRepo.RemoveParticipant(roomID, userID)
+	if err != nil {
+		return err
+	}
+
+	u.recordMembershipChange(room, actorID, fmt.Sprintf("%s removed %s", displayName(actor.User), displayName(target.User)), models.WebhookMembershipData{
+		Action:  MembershipRemoved,
+		ActorID: actorID,
+		UserIDs: []string{userID},
+	})
+	return nil
+}
+
+func (u *chatRoomUsecase) ChangeRole(actorID, roomID, userID, role string) error {
+	room, actor, err := u.findGroupMember(roomID, actorID)
+	if err != nil {
+		return err
+	}
+	target := findParticipant(room, userID)
+	if target == nil {
+		return ErrMemberNotFound
+	}
+	// Only the owner hands out or takes back admin rights
+	if actor.Role != entities.RoleOwner {
+		return ErrRoomPermission
+	}
+	if target.Role == entities.RoleOwner {
+		return ErrOwnerProtection
+	}
+	if target.Role == role {
+		return nil
+	}
+
+	err = u.chatRoomRepo.UpdateParticipantRole(roomID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("%s made %s an admin", displayName(actor.User), displayName(target.User))
+	if role == entities.RoleMember {
+		content = fmt.Sprintf("%s removed %s as admin", displayName(actor.User), displayName(target.User))
+	}
+	u.recordMembershipChange(room, actorID, content, models.WebhookMembershipData{
+		Action:  MembershipRoleChanged,
+		ActorID: actorID,
+		UserIDs: []string{userID},
+		Role:    role,
+	})
+	return nil
+}
+
+func (u *chatRoomUsecase) LeaveRoom(userID, roomID string) error {
+	room, member, err := u.findGroupMember(roomID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = u.chatRoomRepo.RemoveParticipant(roomID, userID)
+	if err != nil {
+		return err
+	}
+
+	var remaining []entities.ChatRoomParticipant
+	for _, v := range room.Participants {
+		if v.UserID != userID {
+			remaining = append(remaining, v)
+		}
+	}
+	if len(remaining) == 0 {
+		return u.chatRoomRepo.DeleteRoom(roomID)
+	}
+
+	content := fmt.Sprintf("%s left", displayName(member.User))
+	if member.Role == entities.RoleOwner {
+		// Hand the room to the longest serving admin, or the longest serving member when there is none
+		sort.Slice(remaining, func(i, j int) bool {
+			if (remaining[i].Role == entities.RoleAdmin) != (remaining[j].Role == entities.RoleAdmin) {
+				return remaining[i].Role == entities.RoleAdmin
+			}
+			return remaining[i].JoinedAt.Before(remaining[j].JoinedAt)
+		})
+		successor := remaining[0]
+		err = u.chatRoomRepo.UpdateParticipantRole(roomID, successor.UserID, entities.RoleOwner)
+		if err != nil {
+			return err
+		}
+		content = fmt.Sprintf("%s left, %s is now the owner", displayName(member.User), displayName(successor.User))
+	}
+
+	u.recordMembershipChange(room, userID, content, models.WebhookMembershipData{
+		Action:  MembershipLeft,
+		ActorID: userID,
+		UserIDs: []string{userID},
+	})
+	return nil
+}
+
+// findGroupMember loads a group room together with the participant entry of userID
+func (u *chatRoomUsecase) findGroupMember(roomID, userID string) (*entities.ChatRoom, *entities.ChatRoomParticipant, error) {
+	room, err := u.chatRoomRepo.FindRoomByID(roomID)
+	if err != nil || room == nil {
+		return nil, nil, ErrRoomNotFound
+	}
+	member := findParticipant(room, userID)
+	if member == nil {
+		return nil, nil, ErrRoomNotFound
+	}
+	if !room.IsGroup {
+		return nil, nil, ErrNotGroupRoom
+	}
+	return room, member, nil
+}
+
+// recordMembershipChange records the change in the room history and notifies the room's webhooks,
+// room is loaded before a removal so the member who left still gets the event and can drop the room
+func (u *chatRoomUsecase) recordMembershipChange(room *entities.ChatRoom, actorID, content string, change models.WebhookMembershipData) {
+	// Subscribers coming and going would flood a channel's history, only the webhooks hear about them
+	quiet := room.IsChannel && (change.Action == MembershipJoined || change.Action == MembershipLeft)
+	if !quiet {
+		u.recordSystemMessage(room, actorID, content)
+	}
+	dispatchWebhook(u.webhooks, room.ID, entities.WebhookEventMembershipChanged, change)
+}
+
+// recordSystemMessage stores a system message in the room history and pushes it to the participants
+func (u *chatRoomUsecase) recordSystemMessage(room *entities.ChatRoom, actorID, content string) {
+	message := &entities.Message{
+		ID:         uuid.New().String(),
+		ChatRoomID: room.ID,
+		SenderID:   actorID,
+		Content:    content,
+		Type:       entities.MessageTypeSystem,
+		Status:     entities.StatusSend,
+	}
+	if err := u.messageRepo.Create(message); err != nil {
+		logging.Log.Errorf("Failed to record system message in room %s: %v", room.ID, err)
+		return
+	}
+
+	var recipients []models.Participants
+	for _, v := range room.Participants {
+		recipients = append(recipients, models.Participants{
+			UserID:     v.UserID,
+			SocketPath: v.User.SocketPath.Path,
+		})
+	}
+
+	publishEvent(u.publisher, Event{
+		Type:       EventMessageNew,
+		RoomID:     room.ID,
+		Recipients: recipients,
+		Data:       message,
+	})
+}
+
+func findParticipant(room *entities.ChatRoom, userID string) *entities.ChatRoomParticipant {
+	for i := range room.Participants {
+		if room.Participants[i].UserID == userID {
+			return &room.Participants[i]
+		}
+	}
+	return nil
+}
+
+func displayName(user entities.User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Username
+}
@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+const maxPinnedMessages = 10
+
+// PinMessage pins a message of the room, in groups only the owner and admins may pin
+func (u *chatRoomUsecase) PinMessage(userID, roomID, messageID string) (*models.PinnedMessageResponse, error) {
+	room, err := u.findPinner(userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := u.messageRepo.FindByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil || message.ChatRoomID != roomID {
+		return nil, errors.New("message not found")
+	}
+	if message.Type == entities.MessageTypeSystem {
+		return nil, errors.New("system messages cannot be pinned")
+	}
+
+	existing, err := u.pinRepo.Find(roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		existing.Message = *message
+		response := mappingPinnedMessage(*existing)
+		return &response, nil
+	}
+
+	pin := &entities.PinnedMessage{
+		ID:         uuid.New().String(),
+		ChatRoomID: roomID,
+		MessageID:  messageID,
+		PinnedBy:   userID,
+	}
+	created, err := u.pinRepo.CreateWithinLimit(pin, maxPinnedMessages)
+	if err != nil {
+		return nil, errors.New("failed to pin message: " + err.Error())
+	}
+	if !created {
+		return nil, fmt.Errorf("a room can have at most %d pinned messages", maxPinnedMessages)
+	}
+	pin.Message = *message
+
+	response := mappingPinnedMessage(*pin)
+	u.publishToRoom(room, EventMessagePinned, response)
+	return &response, nil
+}
+
+func (u *chatRoomUsecase) UnpinMessage(userID, roomID, messageID string) error {
+	room, err := u.findPinner(userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := u.pinRepo.Delete(roomID, messageID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("message is not pinned")
+	}
+
+	u.publishToRoom(room, EventMessageUnpinned, models.PinnedMessageResponse{
+		RoomID:    roomID,
+		MessageID: messageID,
+		PinnedBy:  userID,
+	})
+	return nil
+}
+
+func (u *chatRoomUsecase) GetPinnedMessages(userID, roomID string) ([]models.PinnedMessageResponse, error) {
+	room, err := u.chatRoomRepo.FindRoomByID(roomID)
+	if err != nil || room == nil || findParticipant(room, userID) == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	pins, err := u.pinRepo.FindByRoomID(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.PinnedMessageResponse
+	for _, v := range pins {
+		responses = append(responses, mappingPinnedMessage(v))
+	}
+	return responses, nil
+}
+
+// findPinner loads the room if userID may change its pins
+func (u *chatRoomUsecase) findPinner(userID, roomID string) (*entities.ChatRoom, error) {
+	room, err := u.chatRoomRepo.FindRoomByID(roomID)
+	if err != nil || room == nil {
+		return nil, ErrRoomNotFound
+	}
+	participant := findParticipant(room, userID)
+	if participant == nil {
+		return nil, ErrRoomNotFound
+	}
+	if room.IsGroup && !participant.CanManage() {
+		return nil, ErrRoomPermission
+	}
+	return room, nil
+}
+
+// publishToRoom pushes an event to every participant of the room
+func (u *chatRoomUsecase) publishToRoom(room *entities.ChatRoom, eventType string, data interface{}) {
+	var recipients []models.Participants
+	for _, v := range room.Participants {
+		recipients = append(recipients, models.Participants{
+			UserID:     v.UserID,
+			SocketPath: v.User.SocketPath.Path,
+		})
+	}
+
+	publishEvent(u.publisher, Event{
+		Type:       eventType,
+		RoomID:     room.ID,
+		Recipients: recipients,
+		Data:       data,
+	})
+}
+
+func mappingPinnedMessage(pin entities.PinnedMessage) models.PinnedMessageResponse {
+	return models.PinnedMessageResponse{
+		RoomID:    pin.ChatRoomID,
+		MessageID: pin.MessageID,
+		SenderID:  pin.Message.SenderID,
+		Text:      pin.Message.Content,
+		Time:      pin.Message.CreatedAt.Format("2006-01-02 15:04"),
+		PinnedBy:  pin.PinnedBy,
+		PinnedAt:  pin.CreatedAt.Format("2006-01-02 15:04"),
+	}
+}
@@ -0,0 +1,85 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+)
+
+const maxPinnedRooms = 5
+
+// PinRoom keeps a room at the top of the caller's room list, the setting is per participant
+func (u *chatRoomUsecase) PinRoom(userID, roomID string, pinned bool) (*models.GetChatRoomResponse, error) {
+	room, err := u.chatRoomRepo.FindRoomByID(roomID)
+	if err != nil || room == nil {
+		return nil, ErrRoomNotFound
+	}
+	participant := findParticipant(room, userID)
+	if participant == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	var pinnedAt *time.Time
+	if pinned {
+		if participant.PinnedAt != nil {
+			chatRoom := mappingChatRoom(*room, userID)
+			return &chatRoom, nil
+		}
+		if participant.ArchivedAt != nil {
+			return nil, errors.New("unarchive the room before pinning it")
+		}
+		count, err := u.chatRoomRepo.CountPinned(userID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= maxPinnedRooms {
+			return nil, fmt.Errorf("you can pin at most %d rooms", maxPinnedRooms)
+		}
+		now := time.Now()
+		pinnedAt = &now
+	}
+
+	err = u.chatRoomRepo.SetPinnedAt(roomID, userID, pinnedAt)
+	if err != nil {
+		return nil, errors.New("failed to update room: " + err.Error())
+	}
+
+	participant.PinnedAt = pinnedAt
+	chatRoom := mappingChatRoom(*room, userID)
+	return &chatRoom, nil
+}
+
+// ArchiveRoom moves a room out of the caller's main room list, archived rooms are not pinned
+func (u *chatRoomUsecase) ArchiveRoom(userID, roomID string, archived bool) (*models.GetChatRoomResponse, error) {
+	room, err := u.chatRoomRepo.FindRoomByID(roomID)
+	if err != nil || room == nil {
+		return nil, ErrRoomNotFound
+	}
+	participant := findParticipant(room, userID)
+	if participant == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+		if participant.PinnedAt != nil {
+			if err := u.chatRoomRepo.SetPinnedAt(roomID, userID, nil); err != nil {
+				return nil, errors.New("failed to update room: " + err.Error())
+			}
+			participant.PinnedAt = nil
+		}
+	}
+
+	err = u.chatRoomRepo.SetArchivedAt(roomID, userID, archivedAt)
+	if err != nil {
+		return nil, errors.New("failed to update room: " + err.Error())
+	}
+
+	participant.ArchivedAt = archivedAt
+	chatRoom := mappingChatRoom(*room, userID)
+	return &chatRoom, nil
+}
@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"errors"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/repositories"
+)
+
+// Last seen is only written when it is older than this, to avoid a database write on every request
+const sessionTouchInterval = time.Minute
+
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+type SessionUsecase interface {
+	GetSessions(userID, currentSessionID string) ([]models.SessionResponse, error)
+	RevokeSession(userID, sessionID string) error
+	RevokeOtherSessions(userID, currentSessionID string) error
+	ValidateSession(userID, sessionID string) error
+}
+
+type sessionUsecase struct {
+	sessionRepo repositories.SessionRepository
+}
+
+func NewSessionUsecase(sessionRepo repositories.SessionRepository) SessionUsecase {
+	return &sessionUsecase{sessionRepo: sessionRepo}
+}
+
+func (s *sessionUsecase) GetSessions(userID, currentSessionID string) ([]models.SessionResponse, error) {
+	sessions, err := s.sessionRepo.FindActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.SessionResponse
+	for _, v := range sessions {
+		responses = append(responses, models.SessionResponse{
+			ID:         v.ID,
+			DeviceName: v.DeviceName,
+			UserAgent:  v.UserAgent,
+			IP:         v.IP,
+			LastSeenAt: v.LastSeenAt.Format("2006-01-02 15:04"),
+			CreatedAt:  v.CreatedAt.Format("2006-01-02 15:04"),
+			Current:    v.ID == currentSessionID,
+		})
+	}
+	return responses, nil
+}
+
+func (s *sessionUsecase) RevokeSession(userID, sessionID string) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		return err
+	}
+	// Sessions of other users are reported as missing rather than forbidden
+	if session == nil || session.UserID != userID {
+		return errors.New("session not found")
+	}
+
+	return s.sessionRepo.Revoke(sessionID)
+}
+
+// RevokeOtherSessions signs out every device of the user except the one making the request
+func (s *sessionUsecase) RevokeOtherSessions(userID, currentSessionID string) error {
+	// Without a current session every session would be revoked
+	if currentSessionID == "" {
+		return errors.New("current session not found")
+	}
+	return s.sessionRepo.RevokeAllByUser(userID, currentSessionID)
+}
+
+func (s *sessionUsecase) ValidateSession(userID, sessionID string) error {
+	if sessionID == "" {
+		return ErrSessionRevoked
+	}
+
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return ErrSessionRevoked
+	}
+
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		if err := s.sessionRepo.TouchLastSeen(session.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/oidc"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const ssoLoginStateTTL = 10 * time.Minute
+
+var (
+	ErrSSONotConfigured = errors.New("single sign-on is not configured")
+	ErrInvalidSSOState  = errors.New("invalid or expired single sign-on request")
+
+	usernameInvalidChars = regexp.MustCompile(`[^a-z0-9_.]+`)
+)
+
+func (u *userUsecase) StartSSOLogin(ctx context.Context) (*models.SSOLoginResponse, error) {
+	if u.ssoProvider == nil {
+		return nil, ErrSSONotConfigured
+	}
+
+	state, err := helper.GenerateRandomString(32)
+	if err != nil {
+		return nil, errors.New("failed to generate state")
+	}
+	nonce, err := helper.GenerateRandomString(32)
+	if err != nil {
+		return nil, errors.New("failed to generate nonce")
+	}
+	codeVerifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return nil, errors.New("failed to generate code verifier")
+	}
+
+	err = u.identityRepo.CreateLoginState(&entities.SSOLoginState{
+		State:        helper.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(ssoLoginStateTTL),
+	})
+	if err != nil {
+		return nil, errors.New("failed to store single sign-on request: " + err.Error())
+	}
+
+	authorizationURL, err := u.ssoProvider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SSOLoginResponse{AuthorizationURL: authorizationURL, State: state}, nil
+}
+
+func (u *userUsecase) CompleteSSOLogin(ctx context.Context, code, state string, client models.ClientInfo) (*models.LoginResponse, error) {
+	if u.ssoProvider == nil {
+		return nil, ErrSSONotConfigured
+	}
+
+	loginState, err := u.identityRepo.TakeLoginState(helper.HashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if loginState == nil || time.Now().After(loginState.ExpiresAt) {
+		return nil, ErrInvalidSSOState
+	}
+
+	claims, err := u.ssoProvider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		logging.LogAudit("sso_login_failed", logrus.Fields{"ip": client.IP, "error": err.Error()})
+		return nil, errors.New("single sign-on failed")
+	}
+
+	user, err := u.findOrProvisionSSOUser(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.LogAudit("sso_login_verified", logrus.Fields{"user_id": user.ID, "ip": client.IP, "provider": u.ssoProvider.Issuer()})
+	return u.completeLogin(user, client)
+}
+
+// findOrProvisionSSOUser resolves the local user for an external identity. Unknown identities are linked
+// to an existing account with the same verified email, otherwise a new account is created
+func (u *userUsecase) findOrProvisionSSOUser(claims *oidc.IDTokenClaims) (*entities.User, error) {
+	provider := u.ssoProvider.Issuer()
+
+	identity, err := u.identityRepo.FindByProviderSubject(provider, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := u.userRepo.FindByID(identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, errors.New("linked user no longer exists")
+		}
+		return user, nil
+	}
+
+	// Only trust the email for linking when the provider vouches for it
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errors.New("identity provider did not return a verified email address")
+	}
+
+	user, _ := u.userRepo.FindByEmail(claims.Email)
+	if user == nil {
+		user, err = u.provisionSSOUser(claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = u.identityRepo.Create(&entities.ExternalIdentity{
+		ID:       uuid.New().String(),
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	})
+	if err != nil {
+		return nil, errors.New("failed to link identity: " + err.Error())
+	}
+
+	logging.LogAudit("sso_identity_linked", logrus.Fields{"user_id": user.ID, "provider": provider, "subject": claims.Subject})
+	return user, nil
+}
+
+func (u *userUsecase) provisionSSOUser(claims *oidc.IDTokenClaims) (*entities.User, error) {
+	username, err := u.generateUniqueUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account can only be used through SSO until the user sets a password with the reset flow
+	password, err := helper.GeneratePassword(32)
+	if err != nil {
+		return nil, errors.New("failed to generate password")
+	}
+	hashedPassword, err := helper.HashPassword(password)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	socketID, err := getAvailableSocketPathID(u.userRepo, u.socketPathRepo)
+	if err != nil {
+		return nil, errors.New("failed to assign socket path: " + err.Error())
+	}
+
+	now := time.Now()
+	user := &entities.User{
+		ID:              uuid.New().String(),
+		Username:        username,
+		Email:           claims.Email,
+		Password:        hashedPassword,
+		DisplayName:     claims.Name,
+		EmailVerifiedAt: &now,
+		SocketID:        socketID,
+	}
+	err = u.userRepo.Create(user)
+	if err != nil {
+		return nil, errors.New("failed to provision user: " + err.Error())
+	}
+
+	// Reload to get the socket path used in the token
+	return u.userRepo.FindByID(user.ID)
+}
+
+func (u *userUsecase) generateUniqueUsername(claims *oidc.IDTokenClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base = strings.Split(claims.Email, "@")[0]
+	}
+	base = usernameInvalidChars.ReplaceAllString(strings.ToLower(base), "")
+	if len(base) < 3 {
+		base = "user" + base
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		existingUser, err := u.userRepo.FindByUsername(candidate)
+		if err != nil {
+			return "", err
+		}
+		if existingUser == nil {
+			return candidate, nil
+		}
+
+		suffix, err := helper.GenerateRandomNumber(4)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + suffix
+	}
+	return "", errors.New("failed to generate a unique username")
+}
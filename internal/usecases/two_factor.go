@@ -0,0 +1,248 @@
+package usecases
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"chat-be/internal/config"
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	twoFactorTokenTTL  = 5 * time.Minute
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+func (u *userUsecase) EnrollTwoFactor(userID string) (*models.TwoFactorEnrollResponse, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate two-factor secret")
+	}
+
+	// The secret is kept pending until the user proves their app produces valid codes
+	encryptedSecret, err := helper.Encrypt(secret, totpEncryptionKey())
+	if err != nil {
+		return nil, errors.New("failed to store two-factor secret")
+	}
+	user.TOTPSecret = encryptedSecret
+	err = u.userRepo.Update(user)
+	if err != nil {
+		return nil, errors.New("failed to store two-factor secret: " + err.Error())
+	}
+
+	issuer := config.GetEnv("TOTP_ISSUER", "WeTalk")
+	return &models.TwoFactorEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: helper.TOTPProvisioningURI(secret, user.Email, issuer),
+	}, nil
+}
+
+func (u *userUsecase) ConfirmTwoFactor(userID, code string) ([]string, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("two-factor enrollment has not been started")
+	}
+
+	if !u.validateTOTP(user, code) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	recoveryCodes, err := u.regenerateRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	err = u.userRepo.Update(user)
+	if err != nil {
+		return nil, errors.New("failed to enable two-factor authentication: " + err.Error())
+	}
+
+	logging.LogAudit("two_factor_enabled", logrus.Fields{"user_id": user.ID})
+	return recoveryCodes, nil
+}
+
+func (u *userUsecase) DisableTwoFactor(userID, password, code string) error {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if user.TOTPEnabledAt == nil {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	err = helper.CompareHashAndPassword(user.Password, password)
+	if err != nil {
+		return errors.New("password is incorrect")
+	}
+
+	valid, err := u.verifySecondFactor(user, code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return ErrInvalidTwoFactorCode
+	}
+
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	err = u.userRepo.Update(user)
+	if err != nil {
+		return errors.New("failed to disable two-factor authentication: " + err.Error())
+	}
+
+	err = u.recoveryCodeRepo.DeleteByUser(user.ID)
+	if err != nil {
+		return errors.New("failed to remove recovery codes: " + err.Error())
+	}
+
+	logging.LogAudit("two_factor_disabled", logrus.Fields{"user_id": user.ID})
+	return nil
+}
+
+func (u *userUsecase) LoginTwoFactor(interimToken, code string, client models.ClientInfo) (*models.LoginResponse, error) {
+	claims, err := helper.ValidateTwoFactorToken(interimToken)
+	if err != nil {
+		return nil, err
+	}
+
+	key := twoFactorLoginKey(claims.UserID)
+	err = u.checkKeyLocked(key)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.TOTPEnabledAt == nil {
+		return nil, errors.New("invalid two-factor session")
+	}
+
+	valid, err := u.verifySecondFactor(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		logging.LogAudit("two_factor_failed", logrus.Fields{"user_id": user.ID, "ip": client.IP})
+		u.registerFailure(key, accountLoginPolicy)
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := u.loginAttemptRepo.Delete(key); err != nil {
+		logging.Log.Errorf("Failed to clear two-factor attempts for %s: %v", user.ID, err)
+	}
+	logging.LogAudit("login_succeeded", logrus.Fields{"user_id": user.ID, "ip": client.IP, "two_factor": true})
+
+	return u.issueLoginToken(user, client)
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery code, consuming the latter
+func (u *userUsecase) verifySecondFactor(user *entities.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if u.validateTOTP(user, code) {
+		return true, nil
+	}
+
+	normalized := strings.ToUpper(strings.ReplaceAll(code, "-", ""))
+	if len(normalized) != recoveryCodeLength {
+		return false, nil
+	}
+
+	recoveryCodes, err := u.recoveryCodeRepo.FindUnusedByUser(user.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, recoveryCode := range recoveryCodes {
+		if helper.CompareHashAndPassword(recoveryCode.CodeHash, normalized) == nil {
+			used, err := u.recoveryCodeRepo.MarkUsed(recoveryCode.ID)
+			if err != nil {
+				return false, err
+			}
+			if used {
+				logging.LogAudit("recovery_code_used", logrus.Fields{"user_id": user.ID})
+			}
+			return used, nil
+		}
+	}
+	return false, nil
+}
+
+func (u *userUsecase) validateTOTP(user *entities.User, code string) bool {
+	if user.TOTPSecret == "" {
+		return false
+	}
+	secret, err := helper.Decrypt(user.TOTPSecret, totpEncryptionKey())
+	if err != nil {
+		logging.Log.Errorf("Failed to decrypt two-factor secret for %s: %v", user.ID, err)
+		return false
+	}
+	return helper.ValidateTOTPCode(secret, code, time.Now())
+}
+
+func (u *userUsecase) regenerateRecoveryCodes(userID string) ([]string, error) {
+	var plainCodes []string
+	var recoveryCodes []entities.RecoveryCode
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := helper.GenerateRandomStringCaps(recoveryCodeLength)
+		if err != nil {
+			return nil, errors.New("failed to generate recovery codes")
+		}
+		hashedCode, err := helper.HashPassword(code)
+		if err != nil {
+			return nil, errors.New("failed to hash recovery codes")
+		}
+		plainCodes = append(plainCodes, code)
+		recoveryCodes = append(recoveryCodes, entities.RecoveryCode{
+			ID:       uuid.New().String(),
+			UserID:   userID,
+			CodeHash: hashedCode,
+		})
+	}
+
+	err := u.recoveryCodeRepo.ReplaceForUser(userID, recoveryCodes)
+	if err != nil {
+		return nil, errors.New("failed to store recovery codes: " + err.Error())
+	}
+	return plainCodes, nil
+}
+
+// totpEncryptionKey must be 16, 24 or 32 bytes long to be used as an AES key
+func totpEncryptionKey() string {
+	return config.GetEnv("TOTP_ENCRYPTION_KEY", "wetalk-totp-dev-key-change-me!!!")
+}
@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/repositories"
+)
+
+const EventTypingUpdated = "typing.updated"
+
+const (
+	// A typing indicator disappears on its own when it is not renewed within this window
+	typingExpiry = 6 * time.Second
+	// Repeated start signals from the same user in a room are forwarded at most this often
+	typingThrottle = 3 * time.Second
+)
+
+// TypingUsecase fans typing signals out to the other participants of a room, nothing is stored
+type TypingUsecase interface {
+	HandleTyping(event models.TypingEvent) error
+}
+
+type typingState struct {
+	forwardedAt time.Time
+	expiry      *time.Timer
+}
+
+type typingUsecase struct {
+	chatRoomRepo repositories.ChatRoomRepository
+	publisher    EventPublisher
+
+	mu     sync.Mutex
+	typing map[string]*typingState // Keyed by room and user
+}
+
+func NewTypingUsecase(chatRoomRepo repositories.ChatRoomRepository, publisher EventPublisher) TypingUsecase {
+	return &typingUsecase{
+		chatRoomRepo: chatRoomRepo,
+		publisher:    publisher,
+		typing:       map[string]*typingState{},
+	}
+}
+
+func (t *typingUsecase) HandleTyping(event models.TypingEvent) error {
+	key := event.RoomID + ":" + event.UserID
+	now := time.Now()
+
+	t.mu.Lock()
+	state, active := t.typing[key]
+	if event.Typing {
+		if active && now.Sub(state.forwardedAt) < typingThrottle {
+			// Still shown to the others, only push the expiry back
+			state.expiry.Reset(typingExpiry)
+			t.mu.Unlock()
+			return nil
+		}
+		if active {
+			state.expiry.Reset(typingExpiry)
+			state.forwardedAt = now
+		} else {
+			state = &typingState{forwardedAt: now}
+			state.expiry = time.AfterFunc(typingExpiry, func() {
+				t.expire(key, state, event.RoomID, event.UserID)
+			})
+			t.typing[key] = state
+		}
+	} else {
+		if !active {
+			// The others were never told this user is typing
+			t.mu.Unlock()
+			return nil
+		}
+		state.expiry.Stop()
+		delete(t.typing, key)
+	}
+	t.mu.Unlock()
+
+	err := t.broadcast(event.RoomID, event.UserID, event.Typing)
+	if err != nil && event.Typing {
+		t.mu.Lock()
+		if state, active := t.typing[key]; active {
+			state.expiry.Stop()
+			delete(t.typing, key)
+		}
+		t.mu.Unlock()
+	}
+	return err
+}
+
+// expire only clears the state its timer belongs to, a stop then start may have replaced it
+// while the old callback was already waiting for the lock
+func (t *typingUsecase) expire(key string, state *typingState, roomID, userID string) {
+	t.mu.Lock()
+	active := t.typing[key] == state
+	if active {
+		delete(t.typing, key)
+	}
+	t.mu.Unlock()
+
+	if active {
+		t.broadcast(roomID, userID, false)
+	}
+}
+
+func (t *typingUsecase) broadcast(roomID, userID string, typing bool) error {
+	participants, err := t.chatRoomRepo.FindUsersByRoomID(roomID)
+	if err != nil {
+		return err
+	}
+
+	validSender := false
+	var recipients []models.Participants
+	for _, v := range participants {
+		if v.UserID == userID {
+			validSender = true
+			continue
+		}
+		recipients = append(recipients, models.Participants{
+			UserID:     v.UserID,
+			SocketPath: v.User.SocketPath.Path,
+		})
+	}
+	if !validSender {
+		return errors.New("invalid sender")
+	}
+
+	response := models.TypingResponse{
+		UserID: userID,
+		RoomID: roomID,
+		Typing: typing,
+	}
+	if typing {
+		response.ExpiresIn = int(typingExpiry.Seconds())
+	}
+
+	publishEvent(t.publisher, Event{
+		Type:       EventTypingUpdated,
+		RoomID:     roomID,
+		Recipients: recipients,
+		Data:       response,
+	})
+	return nil
+}
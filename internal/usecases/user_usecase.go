@@ -1,29 +1,78 @@
 package usecases
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"chat-be/internal/config"
+	"chat-be/internal/delivery/http/models"
 	"chat-be/internal/domain/entities"
 	"chat-be/internal/domain/repositories"
+	"chat-be/internal/mailer"
+	"chat-be/internal/oidc"
 	"chat-be/package/helper"
+	"chat-be/package/logging"
 
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type UserUsecase interface {
 	Register(user *entities.User) error
-	Login(email, password string) (string, error)
-	SearchUsers(query string, userID string) ([]entities.UserResponse, error)
+	Login(email, password string, client models.ClientInfo) (*models.LoginResponse, error)
+	SearchUsers(query string, userID string, page, limit int) ([]entities.UserResponse, int64, error)
+	GetProfile(userID string) (*entities.UserResponse, error)
+	UpdateProfile(userID string, request models.UpdateProfileRequest) (*entities.UserResponse, error)
+	ChangePassword(userID, sessionID, oldPassword, newPassword string) error
+	ForgotPassword(email string) error
+	ResetPassword(token, newPassword string) error
+	VerifyEmail(token string) error
+	ResendVerification(email string) error
+	EnrollTwoFactor(userID string) (*models.TwoFactorEnrollResponse, error)
+	ConfirmTwoFactor(userID, code string) ([]string, error)
+	DisableTwoFactor(userID, password, code string) error
+	LoginTwoFactor(interimToken, code string, client models.ClientInfo) (*models.LoginResponse, error)
+	StartSSOLogin(ctx context.Context) (*models.SSOLoginResponse, error)
+	CompleteSSOLogin(ctx context.Context, code, state string, client models.ClientInfo) (*models.LoginResponse, error)
+	GetPrivacySettings(userID string) (*models.PrivacySettingsResponse, error)
+	UpdatePrivacySettings(userID string, request models.PrivacySettingsRequest) (*models.PrivacySettingsResponse, error)
 }
 
+const (
+	passwordResetTokenTTL     = 30 * time.Minute
+	emailVerificationTokenTTL = 24 * time.Hour
+	emailVerificationThrottle = time.Minute
+)
+
 type userUsecase struct {
-	userRepo       repositories.UserRepository
-	socketPathRepo repositories.SocketPathRepository
+	userRepo          repositories.UserRepository
+	socketPathRepo    repositories.SocketPathRepository
+	passwordResetRepo repositories.PasswordResetRepository
+	loginAttemptRepo  repositories.LoginAttemptRepository
+	recoveryCodeRepo  repositories.RecoveryCodeRepository
+	sessionRepo       repositories.SessionRepository
+	identityRepo      repositories.ExternalIdentityRepository
+	mailer            mailer.Mailer
+	// ssoProvider is nil when single sign-on is not configured
+	ssoProvider *oidc.Provider
 }
 
-func NewUserUsecase(userRepo repositories.UserRepository, socketPathRepo repositories.SocketPathRepository) UserUsecase {
-	return &userUsecase{userRepo: userRepo, socketPathRepo: socketPathRepo}
+func NewUserUsecase(userRepo repositories.UserRepository, socketPathRepo repositories.SocketPathRepository, passwordResetRepo repositories.PasswordResetRepository, loginAttemptRepo repositories.LoginAttemptRepository, recoveryCodeRepo repositories.RecoveryCodeRepository, sessionRepo repositories.SessionRepository, identityRepo repositories.ExternalIdentityRepository, mailer mailer.Mailer, ssoProvider *oidc.Provider) UserUsecase {
+	return &userUsecase{
+		userRepo:          userRepo,
+		socketPathRepo:    socketPathRepo,
+		passwordResetRepo: passwordResetRepo,
+		loginAttemptRepo:  loginAttemptRepo,
+		recoveryCodeRepo:  recoveryCodeRepo,
+		sessionRepo:       sessionRepo,
+		identityRepo:      identityRepo,
+		mailer:            mailer,
+		ssoProvider:       ssoProvider,
+	}
 }
 
 func (u *userUsecase) Register(user *entities.User) error {
@@ -46,7 +95,7 @@ func (u *userUsecase) Register(user *entities.User) error {
 	user.Password = string(hashedPassword)
 
 	// Assign available socket path ID
-	socketID, err := u.getAvailableSocketPathID()
+	socketID, err := getAvailableSocketPathID(u.userRepo, u.socketPathRepo)
 	if err != nil {
 		return errors.New("failed to assign socket path: " + err.Error())
 	}
@@ -58,62 +107,398 @@ func (u *userUsecase) Register(user *entities.User) error {
 		return errors.New("failed to register user: " + err.Error())
 	}
 
+	// The account exists at this point, a failed email can be retried through the resend endpoint
+	if err := u.sendVerificationEmail(user); err != nil {
+		logging.Log.Errorf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+
 	return nil
 }
 
-func (u *userUsecase) Login(email, password string) (string, error) {
+func (u *userUsecase) Login(email, password string, client models.ClientInfo) (*models.LoginResponse, error) {
+	err := u.checkLoginLocked(email, client.IP)
+	if err != nil {
+		return nil, err
+	}
+
 	// Find user by email
 	user, err := u.userRepo.FindByEmail(email)
+	if err != nil || user == nil {
+		u.recordFailedLogin(email, client.IP)
+		return nil, ErrInvalidCredentials
+	}
+
+	// Check password, bots can only authenticate with API keys
+	err = helper.CompareHashAndPassword(user.Password, password)
+	if err != nil || user.IsBot {
+		u.recordFailedLogin(email, client.IP)
+		return nil, ErrInvalidCredentials
+	}
+
+	u.clearFailedLogins(email)
+
+	return u.completeLogin(user, client)
+}
+
+// completeLogin runs the checks shared by every way of proving identity and then issues a token,
+// or an interim token when a second factor is still required
+func (u *userUsecase) completeLogin(user *entities.User, client models.ClientInfo) (*models.LoginResponse, error) {
+	err := requireVerifiedEmail(user, EmailVerificationLogin)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if user == nil {
-		return "", errors.New("invalid credentials")
+
+	// A second factor is still needed, hand out a short lived token for the TOTP step
+	if user.TOTPEnabledAt != nil {
+		interimToken, err := helper.GenerateTwoFactorToken(user.ID, user.Email, twoFactorTokenTTL)
+		if err != nil {
+			return nil, errors.New("internal server error")
+		}
+		logging.LogAudit("login_first_factor_verified", logrus.Fields{"user_id": user.ID, "ip": client.IP})
+		return &models.LoginResponse{TwoFactorRequired: true, InterimToken: interimToken}, nil
 	}
 
-	// Check password
-	err = helper.CompareHashAndPassword(user.Password, password)
+	logging.LogAudit("login_succeeded", logrus.Fields{"user_id": user.ID, "ip": client.IP})
+	return u.issueLoginToken(user, client)
+}
+
+// issueLoginToken records a session for the device and returns a JWT bound to it
+func (u *userUsecase) issueLoginToken(user *entities.User, client models.ClientInfo) (*models.LoginResponse, error) {
+	now := time.Now()
+	session := &entities.Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		DeviceName: client.DeviceName,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(helper.TokenTTL),
+	}
+	err := u.sessionRepo.Create(session)
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, errors.New("failed to create session: " + err.Error())
 	}
 
 	// Generate JWT
-	token, err := helper.GenerateToken(user.ID, user.Email, user.Username, user.SocketPath.Path)
+	token, err := helper.GenerateToken(user.ID, user.Email, user.Username, user.SocketPath.Path, session.ID)
 	if err != nil {
-		return "", errors.New("internal server error")
+		return nil, errors.New("internal server error")
 	}
-	return token, nil
+	return &models.LoginResponse{Token: token}, nil
 }
 
-func (u *userUsecase) SearchUsers(query string, userID string) ([]entities.UserResponse, error) {
-	// Use repository to search for users by username or email
-	users, err := u.userRepo.SearchByUsernameOrEmail(query)
+// SearchUsers ranks users by exact username, then username prefix, then similar usernames.
+// Emails only match on an exact query and are blanked otherwise, so searching cannot be used to harvest them.
+func (u *userUsecase) SearchUsers(query string, userID string, page, limit int) ([]entities.UserResponse, int64, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	if query == "" {
+		return nil, 0, errors.New("search query is required")
+	}
+
+	users, total, err := u.userRepo.SearchUsers(repositories.UserSearchQuery{
+		Text:       query,
+		SearcherID: userID,
+		Offset:     (page - 1) * limit,
+		Limit:      limit,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to search users: %w", err)
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
 	}
 
 	var userResponses []entities.UserResponse
 	for _, user := range users {
-		if user.ID != userID {
-			userResponses = append(userResponses, entities.UserResponse{
-				ID:       user.ID,
-				Username: user.Username,
-				Email:    user.Email,
-			})
+		response := mappingUserResponse(user)
+		if !strings.EqualFold(user.Email, query) {
+			response.Email = ""
+		}
+		userResponses = append(userResponses, response)
+	}
+	return userResponses, total, nil
+}
+
+func (u *userUsecase) GetPrivacySettings(userID string) (*models.PrivacySettingsResponse, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+	return mappingPrivacySettings(*user), nil
+}
+
+func (u *userUsecase) UpdatePrivacySettings(userID string, request models.PrivacySettingsRequest) (*models.PrivacySettingsResponse, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	if request.Discoverable != nil {
+		user.Discoverable = *request.Discoverable
+	}
+	if request.ContactsOnly != nil {
+		user.ContactsOnly = *request.ContactsOnly
+	}
+	err = u.userRepo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	return mappingPrivacySettings(*user), nil
+}
+
+func mappingPrivacySettings(user entities.User) *models.PrivacySettingsResponse {
+	return &models.PrivacySettingsResponse{
+		Discoverable: user.Discoverable,
+		ContactsOnly: user.ContactsOnly,
+	}
+}
+
+func (u *userUsecase) GetProfile(userID string) (*entities.UserResponse, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	response := mappingUserResponse(*user)
+	return &response, nil
+}
+
+func (u *userUsecase) UpdateProfile(userID string, request models.UpdateProfileRequest) (*entities.UserResponse, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	if request.Username != nil {
+		username := strings.TrimSpace(*request.Username)
+		if username == "" {
+			return nil, errors.New("username cannot be empty")
+		}
+
+		// Make sure nobody else already owns the new username
+		if username != user.Username {
+			existingUser, err := u.userRepo.FindByUsername(username)
+			if err != nil {
+				return nil, err
+			}
+			if existingUser != nil {
+				return nil, errors.New("username already exists")
+			}
+			user.Username = username
+		}
+	}
+	if request.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(*request.DisplayName)
+	}
+	if request.Bio != nil {
+		user.Bio = strings.TrimSpace(*request.Bio)
+	}
+	if request.Avatar != nil {
+		user.Avatar = *request.Avatar
+	}
+
+	err = u.userRepo.Update(user)
+	if err != nil {
+		return nil, errors.New("failed to update profile: " + err.Error())
+	}
+
+	response := mappingUserResponse(*user)
+	return &response, nil
+}
+
+func (u *userUsecase) ChangePassword(userID, sessionID, oldPassword, newPassword string) error {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	err = helper.CompareHashAndPassword(user.Password, oldPassword)
+	if err != nil {
+		return errors.New("old password is incorrect")
+	}
+
+	hashedPassword, err := helper.HashPassword(newPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+	user.Password = hashedPassword
+
+	err = u.userRepo.Update(user)
+	if err != nil {
+		return errors.New("failed to change password: " + err.Error())
+	}
+
+	// Sign out every other device, the one that changed the password stays logged in
+	err = u.sessionRepo.RevokeAllByUser(user.ID, sessionID)
+	if err != nil {
+		return errors.New("failed to revoke sessions: " + err.Error())
+	}
+
+	return nil
+}
+
+func (u *userUsecase) ForgotPassword(email string) error {
+	user, _ := u.userRepo.FindByEmail(email)
+	if user == nil {
+		// Do not reveal whether the email is registered
+		return nil
+	}
+
+	// From here on failures are only logged, an error would tell the caller the email is registered
+	token, err := helper.GenerateRandomString(48)
+	if err != nil {
+		logging.Log.Errorf("Failed to generate reset token for user %s: %v", user.ID, err)
+		return nil
+	}
+
+	resetToken := &entities.PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTokenTTL),
+	}
+	err = u.passwordResetRepo.Create(resetToken)
+	if err != nil {
+		logging.Log.Errorf("Failed to create reset token for user %s: %v", user.ID, err)
+		return nil
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.GetEnv("APP_URL", "http://localhost:3000"), token)
+	body := fmt.Sprintf("Hi %s,\n\nUse the link below to reset your password. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not request a password reset you can ignore this email.", user.Username, int(passwordResetTokenTTL.Minutes()), link)
+	err = u.mailer.Send(user.Email, "Reset your password", body)
+	if err != nil {
+		logging.Log.Errorf("Failed to send reset email to user %s: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+func (u *userUsecase) ResetPassword(token, newPassword string) error {
+	resetToken, err := u.passwordResetRepo.FindByTokenHash(helper.HashToken(token))
+	if err != nil {
+		return err
+	}
+	if resetToken == nil || resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		return errors.New("invalid or expired reset token")
+	}
+
+	hashedPassword, err := helper.HashPassword(newPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	err = u.passwordResetRepo.ResetPassword(resetToken, hashedPassword)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("invalid or expired reset token")
 		}
+		return errors.New("failed to reset password: " + err.Error())
+	}
+
+	err = u.sessionRepo.RevokeAllByUser(resetToken.UserID, "")
+	if err != nil {
+		return errors.New("failed to revoke sessions: " + err.Error())
+	}
+
+	return nil
+}
+
+func (u *userUsecase) VerifyEmail(token string) error {
+	claims, err := helper.ValidateEmailVerificationToken(token)
+	if err != nil {
+		return err
+	}
+
+	user, err := u.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return err
+	}
+	// The link is bound to the address it was sent to
+	if user == nil || user.Email != claims.Email {
+		return errors.New("invalid verification link")
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	err = u.userRepo.Update(user)
+	if err != nil {
+		return errors.New("failed to verify email: " + err.Error())
+	}
+
+	return nil
+}
+
+func (u *userUsecase) ResendVerification(email string) error {
+	user, _ := u.userRepo.FindByEmail(email)
+	if user == nil || user.EmailVerifiedAt != nil {
+		// Do not reveal whether the email is registered or already verified
+		return nil
+	}
+
+	// From here on the caller gets the same answer as for an unknown address, a throttle or failure is only logged
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < emailVerificationThrottle {
+		logging.Log.Infof("Verification email for user %s was sent recently, not resending", user.ID)
+		return nil
+	}
+
+	if err := u.sendVerificationEmail(user); err != nil {
+		logging.Log.Errorf("Failed to resend verification email to user %s: %v", user.ID, err)
 	}
-	return userResponses, nil
+	return nil
+}
 
+func (u *userUsecase) sendVerificationEmail(user *entities.User) error {
+	token, err := helper.GenerateEmailVerificationToken(user.ID, user.Email, emailVerificationTokenTTL)
+	if err != nil {
+		return errors.New("failed to generate verification token")
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", config.GetEnv("APP_URL", "http://localhost:3000"), token)
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %d hours.\n\n%s", user.Username, int(emailVerificationTokenTTL.Hours()), link)
+	err = u.mailer.Send(user.Email, "Verify your email address", body)
+	if err != nil {
+		return errors.New("failed to send verification email: " + err.Error())
+	}
+
+	now := time.Now()
+	user.VerificationSentAt = &now
+	return u.userRepo.Update(user)
+}
+
+func mappingUserResponse(user entities.User) entities.UserResponse {
+	return entities.UserResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		Avatar:        user.Avatar,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		TwoFactor:     user.TOTPEnabledAt != nil,
+		IsBot:         user.IsBot,
+		Online:        isOnline(user),
+		LastSeenAt:    formatLastSeen(user),
+	}
 }
 
-func (u *userUsecase) getAvailableSocketPathID() (string, error) {
-	socketPaths, err := u.socketPathRepo.FindAll()
+// getAvailableSocketPathID picks a socket path with room for another user, creating one when all are full
+func getAvailableSocketPathID(userRepo repositories.UserRepository, socketPathRepo repositories.SocketPathRepository) (string, error) {
+	socketPaths, err := socketPathRepo.FindAll()
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch socket paths: %w", err)
 	}
 
 	for _, socketPath := range socketPaths {
-		userCount, err := u.userRepo.CountUsersBySocketID(socketPath.ID)
+		userCount, err := userRepo.CountUsersBySocketID(socketPath.ID)
 		if err != nil {
 			return "", fmt.Errorf("failed to count users for socket ID %s: %w", socketPath.ID, err)
 		}
@@ -122,7 +507,7 @@ func (u *userUsecase) getAvailableSocketPathID() (string, error) {
 		}
 	}
 
-	newSocketPathID, err := u.createNewSocketPath()
+	newSocketPathID, err := createNewSocketPath(socketPathRepo)
 	if err != nil {
 		return "", fmt.Errorf("failed to create a new socket path: %w", err)
 	}
@@ -130,7 +515,7 @@ func (u *userUsecase) getAvailableSocketPathID() (string, error) {
 	return newSocketPathID, nil
 }
 
-func (u *userUsecase) createNewSocketPath() (string, error) {
+func createNewSocketPath(socketPathRepo repositories.SocketPathRepository) (string, error) {
 	// Generate a new UUID for the socket path
 	newSocketPathID := uuid.New().String()
 
@@ -141,7 +526,7 @@ func (u *userUsecase) createNewSocketPath() (string, error) {
 	}
 
 	// Save the new socket path to the database
-	err := u.socketPathRepo.Create(newSocketPath)
+	err := socketPathRepo.Create(newSocketPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to save new socket path: %w", err)
 	}
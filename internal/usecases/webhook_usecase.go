@@ -0,0 +1,252 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/domain/repositories"
+	"chat-be/package/helper"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const webhookSecretLength = 32
+
+// WebhookDispatcher queues a room event for every webhook of the room subscribed to it,
+// the deliveries are sent by the webhook worker so callers never wait on receivers
+type WebhookDispatcher interface {
+	Dispatch(roomID, event string, data interface{})
+}
+
+type WebhookUsecase interface {
+	WebhookDispatcher
+	CreateWebhook(userID, roomID string, request models.CreateWebhookRequest) (*models.WebhookResponse, error)
+	GetWebhooks(userID, roomID string) ([]models.WebhookResponse, error)
+	DeleteWebhook(userID, roomID, webhookID string) error
+	GetDeliveries(userID, roomID, webhookID string, page int, limit int) ([]models.WebhookDeliveryResponse, int64, error)
+}
+
+type webhookUsecase struct {
+	webhookRepo  repositories.WebhookRepository
+	chatRoomRepo repositories.ChatRoomRepository
+}
+
+func NewWebhookUsecase(webhookRepo repositories.WebhookRepository, chatRoomRepo repositories.ChatRoomRepository) WebhookUsecase {
+	return &webhookUsecase{
+		webhookRepo:  webhookRepo,
+		chatRoomRepo: chatRoomRepo,
+	}
+}
+
+func (u *webhookUsecase) CreateWebhook(userID, roomID string, request models.CreateWebhookRequest) (*models.WebhookResponse, error) {
+	if err := u.checkRoomAdmin(userID, roomID); err != nil {
+		return nil, err
+	}
+
+	parsedURL, err := url.Parse(strings.TrimSpace(request.URL))
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return nil, errors.New("webhook URL must be an http or https URL")
+	}
+
+	secret, err := helper.GenerateRandomString(webhookSecretLength)
+	if err != nil {
+		return nil, errors.New("failed to generate webhook secret")
+	}
+
+	webhook := &entities.Webhook{
+		ID:         uuid.New().String(),
+		ChatRoomID: roomID,
+		URL:        parsedURL.String(),
+		Secret:     "whsec_" + secret,
+		Events:     request.Events,
+		CreatedBy:  userID,
+	}
+	err = u.webhookRepo.Create(webhook)
+	if err != nil {
+		return nil, errors.New("failed to create webhook: " + err.Error())
+	}
+
+	logging.LogAudit("webhook_created", logrus.Fields{"user_id": userID, "room_id": roomID, "webhook_id": webhook.ID, "events": webhook.Events})
+
+	response := mappingWebhook(*webhook)
+	response.Secret = webhook.Secret
+	return &response, nil
+}
+
+func (u *webhookUsecase) GetWebhooks(userID, roomID string) ([]models.WebhookResponse, error) {
+	if err := u.checkRoomAdmin(userID, roomID); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := u.webhookRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.WebhookResponse
+	for _, v := range webhooks {
+		responses = append(responses, mappingWebhook(v))
+	}
+	return responses, nil
+}
+
+func (u *webhookUsecase) DeleteWebhook(userID, roomID, webhookID string) error {
+	if _, err := u.findWebhook(userID, roomID, webhookID); err != nil {
+		return err
+	}
+
+	err := u.webhookRepo.Delete(webhookID)
+	if err != nil {
+		return err
+	}
+
+	logging.LogAudit("webhook_deleted", logrus.Fields{"user_id": userID, "room_id": roomID, "webhook_id": webhookID})
+	return nil
+}
+
+func (u *webhookUsecase) GetDeliveries(userID, roomID, webhookID string, page int, limit int) ([]models.WebhookDeliveryResponse, int64, error) {
+	if _, err := u.findWebhook(userID, roomID, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	deliveries, total, err := u.webhookRepo.FindDeliveriesByWebhookID(webhookID, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []models.WebhookDeliveryResponse
+	for _, v := range deliveries {
+		responses = append(responses, mappingWebhookDelivery(v))
+	}
+	return responses, total, nil
+}
+
+func (u *webhookUsecase) Dispatch(roomID, event string, data interface{}) {
+	webhooks, err := u.webhookRepo.FindByRoomID(roomID)
+	if err != nil {
+		logging.Log.Errorf("Failed to load webhooks for room %s: %v", roomID, err)
+		return
+	}
+
+	now := time.Now()
+	var deliveries []entities.WebhookDelivery
+	for _, v := range webhooks {
+		if !containsString(v.Events, event) {
+			continue
+		}
+
+		deliveryID := uuid.New().String()
+		payload, err := json.Marshal(models.WebhookPayload{
+			ID:        deliveryID,
+			Event:     event,
+			RoomID:    roomID,
+			CreatedAt: now.UTC().Format(time.RFC3339),
+			Data:      data,
+		})
+		if err != nil {
+			logging.Log.Errorf("Failed to encode %s webhook payload: %v", event, err)
+			return
+		}
+
+		deliveries = append(deliveries, entities.WebhookDelivery{
+			ID:            deliveryID,
+			WebhookID:     v.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        entities.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+
+	if err := u.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		logging.Log.Errorf("Failed to queue %s webhook deliveries: %v", event, err)
+	}
+}
+
+func (u *webhookUsecase) findWebhook(userID, roomID, webhookID string) (*entities.Webhook, error) {
+	if err := u.checkRoomAdmin(userID, roomID); err != nil {
+		return nil, err
+	}
+
+	webhook, err := u.webhookRepo.FindByID(webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil || webhook.ChatRoomID != roomID {
+		return nil, errors.New("webhook not found")
+	}
+	return webhook, nil
+}
+
+// checkRoomAdmin allows both members of a direct room and the owner or admins of a group to manage its webhooks
+func (u *webhookUsecase) checkRoomAdmin(userID, roomID string) error {
+	room, err := u.chatRoomRepo.FindRoomByID(roomID)
+	if err != nil || room == nil {
+		return errors.New("room not found")
+	}
+
+	for _, v := range room.Participants {
+		if v.UserID != userID {
+			continue
+		}
+		if !room.IsGroup || v.CanManage() {
+			return nil
+		}
+		break
+	}
+	return errors.New("only room admins can manage webhooks")
+}
+
+// dispatchWebhook is a no-op when webhooks are not configured
+func dispatchWebhook(dispatcher WebhookDispatcher, roomID, event string, data interface{}) {
+	if dispatcher == nil {
+		return
+	}
+	dispatcher.Dispatch(roomID, event, data)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func mappingWebhook(webhook entities.Webhook) models.WebhookResponse {
+	return models.WebhookResponse{
+		ID:        webhook.ID,
+		RoomID:    webhook.ChatRoomID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		CreatedAt: webhook.CreatedAt.Format("2006-01-02 15:04"),
+	}
+}
+
+func mappingWebhookDelivery(delivery entities.WebhookDelivery) models.WebhookDeliveryResponse {
+	response := models.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt.Format("2006-01-02 15:04"),
+	}
+	if delivery.Status == entities.WebhookDeliveryPending {
+		response.NextAttemptAt = delivery.NextAttemptAt.Format("2006-01-02 15:04")
+	}
+	if delivery.DeliveredAt != nil {
+		response.DeliveredAt = delivery.DeliveredAt.Format("2006-01-02 15:04")
+	}
+	return response
+}
@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/domain/repositories"
+	"chat-be/package/logging"
+
+	"github.com/google/uuid"
+)
+
+// Headers sent with every delivery, receivers verify the signature over "<timestamp>.<body>"
+const (
+	EventHeader     = "X-WeTalk-Event"
+	DeliveryHeader  = "X-WeTalk-Delivery"
+	TimestampHeader = "X-WeTalk-Timestamp"
+	SignatureHeader = "X-WeTalk-Signature"
+)
+
+const (
+	defaultInterval    = 5 * time.Second
+	defaultBatchSize   = 50
+	defaultMaxAttempts = 6
+	baseRetryDelay     = 30 * time.Second
+	// A claimed delivery is retried by another worker if it is not finished within the lease
+	claimLease = 2 * time.Minute
+)
+
+// Sign returns the signature for a payload sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature is what a receiver runs to check a delivery came from us
+func VerifySignature(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Worker sends pending webhook deliveries in the background, retrying failures with exponential backoff
+type Worker struct {
+	Repo        repositories.WebhookRepository
+	Client      *http.Client
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+}
+
+func NewWorker(repo repositories.WebhookRepository) *Worker {
+	return &Worker{
+		Repo:        repo,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		Interval:    defaultInterval,
+		BatchSize:   defaultBatchSize,
+		MaxAttempts: defaultMaxAttempts,
+	}
+}
+
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.ProcessDue()
+		}
+	}
+}
+
+// ProcessDue sends every delivery that is due now
+func (w *Worker) ProcessDue() {
+	requestID := uuid.New().String()
+	ctx := context.WithValue(context.Background(), logging.RequestIDKey, requestID)
+
+	deliveries, err := w.Repo.ClaimDueDeliveries(w.BatchSize, claimLease)
+	if err != nil {
+		logging.LogError(ctx, "Failed to claim webhook deliveries: %v", err)
+		return
+	}
+
+	// Deliveries are sent in parallel so a batch of slow receivers still finishes within the lease
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *entities.WebhookDelivery) {
+			defer wg.Done()
+			w.process(ctx, delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+}
+
+func (w *Worker) process(ctx context.Context, delivery *entities.WebhookDelivery) {
+	delivery.Attempts++
+
+	if delivery.Webhook.ID == "" {
+		delivery.Status = entities.WebhookDeliveryFailed
+		delivery.LastError = "webhook was deleted"
+	} else {
+		statusCode, err := w.Send(delivery.Webhook, *delivery)
+		delivery.LastStatusCode = statusCode
+		if err == nil {
+			now := time.Now()
+			delivery.Status = entities.WebhookDeliverySucceeded
+			delivery.DeliveredAt = &now
+			delivery.LastError = ""
+		} else {
+			delivery.LastError = err.Error()
+			if delivery.Attempts >= w.MaxAttempts {
+				delivery.Status = entities.WebhookDeliveryFailed
+			} else {
+				delivery.NextAttemptAt = time.Now().Add(RetryDelay(delivery.Attempts))
+			}
+			logging.LogWarning(ctx, "Webhook delivery %s attempt %d failed: %v", delivery.ID, delivery.Attempts, err)
+		}
+	}
+
+	if err := w.Repo.UpdateDelivery(delivery); err != nil {
+		logging.LogError(ctx, "Failed to update webhook delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// Send posts a single delivery and treats any non 2xx response as a failure
+func (w *Worker) Send(webhook entities.Webhook, delivery entities.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "WeTalk-Webhook/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, errors.New(fmt.Sprintf("unexpected status %d", resp.StatusCode))
+	}
+	return resp.StatusCode, nil
+}
+
+// RetryDelay doubles the wait after every failed attempt
+func RetryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return baseRetryDelay << (attempts - 1)
+}
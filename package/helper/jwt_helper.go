@@ -14,17 +14,59 @@ type Claims struct {
 	Email         string `json:"email"`
 	Username      string `json:"username"`
 	SocketGroupID string `json:"socket_group_id"`
+	SessionID     string `json:"session_id"`
+	// Set on single flow tokens only, see PurposeClaims
+	Purpose string `json:"purpose,omitempty"`
+	// Only set when the request was authenticated with a bot API key
+	APIKeyID string   `json:"-"`
+	Scopes   []string `json:"-"`
+	RoomIDs  []string `json:"-"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID, email, username, SocketID string) (string, error) {
+// IsAPIKey reports whether the claims come from a bot API key instead of a login token
+func (c *Claims) IsAPIKey() bool {
+	return c.APIKeyID != ""
+}
+
+// HasScope reports whether the principal was granted scope, login tokens are not restricted
+func (c *Claims) HasScope(scope string) bool {
+	if !c.IsAPIKey() {
+		return true
+	}
+	for _, v := range c.Scopes {
+		if v == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRoom reports whether the principal may act in roomID, API keys can be limited to a set of rooms
+func (c *Claims) AllowsRoom(roomID string) bool {
+	if !c.IsAPIKey() || len(c.RoomIDs) == 0 {
+		return true
+	}
+	for _, v := range c.RoomIDs {
+		if v == roomID {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenTTL is how long a login token, and the session it belongs to, stays valid
+const TokenTTL = 15 * time.Hour
+
+func GenerateToken(userID, email, username, SocketID, sessionID string) (string, error) {
 	claims := &Claims{
 		UserID:        userID,
 		Email:         email,
 		Username:      username,
 		SocketGroupID: SocketID,
+		SessionID:     sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -48,10 +90,95 @@ func ValidateToken(tokenString string) (*Claims, error) {
 		return nil, err
 	}
 
+	// Purpose tokens are signed with the same secret, they must never pass as a login token
 	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || claims.Purpose != "" {
 		return nil, errors.New("invalid token")
 	}
 
 	return claims, nil
 }
+
+const (
+	emailVerificationPurpose = "email_verification"
+	twoFactorPurpose         = "two_factor"
+)
+
+// PurposeClaims are carried by short lived tokens that are only valid for a single flow,
+// ValidateToken rejects them because of the purpose claim
+type PurposeClaims struct {
+	UserID  string `json:"user_id"`
+	Email   string `json:"email"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+func generatePurposeToken(purpose, userID, email string, ttl time.Duration) (string, error) {
+	claims := &PurposeClaims{
+		UserID:  userID,
+		Email:   email,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
+
+func validatePurposeToken(tokenString, purpose string) (*PurposeClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &PurposeClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return jwtSecret, nil
+	})
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, jwt.ErrTokenExpired
+		}
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*PurposeClaims)
+	if !ok || !token.Valid || claims.Purpose != purpose {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
+
+// GenerateEmailVerificationToken signs a token that proves ownership of email for the given user
+func GenerateEmailVerificationToken(userID, email string, ttl time.Duration) (string, error) {
+	return generatePurposeToken(emailVerificationPurpose, userID, email, ttl)
+}
+
+func ValidateEmailVerificationToken(tokenString string) (*PurposeClaims, error) {
+	claims, err := validatePurposeToken(tokenString, emailVerificationPurpose)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, errors.New("verification link has expired")
+		}
+		return nil, errors.New("invalid verification link")
+	}
+	return claims, nil
+}
+
+// GenerateTwoFactorToken signs the interim token handed out after a correct password when a second factor is still required
+func GenerateTwoFactorToken(userID, email string, ttl time.Duration) (string, error) {
+	return generatePurposeToken(twoFactorPurpose, userID, email, ttl)
+}
+
+func ValidateTwoFactorToken(tokenString string) (*PurposeClaims, error) {
+	claims, err := validatePurposeToken(tokenString, twoFactorPurpose)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, errors.New("two-factor session has expired, please log in again")
+		}
+		return nil, errors.New("invalid two-factor session")
+	}
+	return claims, nil
+}
@@ -4,7 +4,9 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"math/big"
@@ -88,6 +90,12 @@ func CompareHashAndPassword(storedHash string, password string) error {
 	return bcrypt.CompareHashAndPassword(hashBytes, []byte(password))
 }
 
+// HashToken returns the hex encoded SHA-256 digest of a random token so it can be stored and looked up
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func GenerateBcryptSalt() (string, error) {
 	// Generate 16 random bytes for the salt
 	saltBytes := make([]byte, 16)
@@ -70,3 +70,9 @@ func LogError(ctx context.Context, message string, args ...interface{}) {
 	logID, _ := ctx.Value(RequestIDKey).(string)
 	LogCustomField(logrus.ErrorLevel, logrus.Fields{"request_id": logID}, message, args...)
 }
+
+// LogAudit logs a security relevant event so it can be filtered from regular logs
+func LogAudit(event string, fields logrus.Fields) {
+	fields["audit"] = event
+	LogCustomField(logrus.WarnLevel, fields, "Audit event: %s", event)
+}
@@ -11,12 +11,52 @@ import (
 // Define a custom type for the context key to avoid collisions
 type contextKey string
 
-const userContextKey contextKey = "user"
+const (
+	userContextKey        contextKey = "user"
+	apiKeyScopeContextKey contextKey = "api_key_scope"
+)
+
+// SessionValidator reports an error when the session a token was issued for is no longer active
+type SessionValidator func(userID, sessionID string) error
+
+var sessionValidator SessionValidator
+
+// SetSessionValidator makes AuthMiddleware reject tokens whose session has been revoked
+func SetSessionValidator(validator SessionValidator) {
+	sessionValidator = validator
+}
+
+// APIKeyAuthenticator resolves a bot API key to the claims of the bot it belongs to
+type APIKeyAuthenticator func(key string) (*helper.Claims, error)
+
+var apiKeyAuthenticator APIKeyAuthenticator
+
+// SetAPIKeyAuthenticator enables authenticating bots with API keys in AuthMiddleware
+func SetAPIKeyAuthenticator(authenticator APIKeyAuthenticator) {
+	apiKeyAuthenticator = authenticator
+}
+
+// AllowAPIKey lets bot API keys granted scope through the AuthMiddleware that follows it.
+// Routes without it only accept login tokens
+func AllowAPIKey(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), apiKeyScopeContextKey, scope)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-// AuthMiddleware checks if the JWT is valid and sets the user context
+// AuthMiddleware checks if the JWT or bot API key is valid and sets the user context
 func AuthMiddleware(next http.Handler) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Bots authenticate with an API key instead of a login token
+		if apiKey := getAPIKey(r); apiKey != "" {
+			authenticateAPIKey(w, r, apiKey, next)
+			return
+		}
+
 		// Get the token from the Authorization header
 		tokenString := r.Header.Get("Authorization")
 		if tokenString == "" {
@@ -35,6 +75,14 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		if sessionValidator != nil {
+			if err := sessionValidator(claims.UserID, claims.SessionID); err != nil {
+				log.Println("Invalid session :", err)
+				WriteResponse(w, http.StatusUnauthorized, "Session is no longer valid", nil)
+				return
+			}
+		}
+
 		// Set the user information in the context
 		ctx := r.Context()
 		ctx = context.WithValue(ctx, userContextKey, claims)
@@ -44,6 +92,39 @@ func AuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+func getAPIKey(r *http.Request) string {
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		return apiKey
+	}
+	if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bot ") {
+		return strings.TrimPrefix(authorization, "Bot ")
+	}
+	return ""
+}
+
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, apiKey string, next http.Handler) {
+	if apiKeyAuthenticator == nil {
+		WriteResponse(w, http.StatusUnauthorized, "API keys are not accepted", nil)
+		return
+	}
+
+	claims, err := apiKeyAuthenticator(apiKey)
+	if err != nil {
+		log.Println("Invalid API key :", err)
+		WriteResponse(w, http.StatusUnauthorized, "Invalid API key", nil)
+		return
+	}
+
+	scope, _ := r.Context().Value(apiKeyScopeContextKey).(string)
+	if scope == "" || !claims.HasScope(scope) {
+		WriteResponse(w, http.StatusForbidden, "API key is not allowed to access this endpoint", nil)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), userContextKey, claims)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
 func GuestMiddleware(next http.Handler) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"chat-be/package/logging"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,7 +34,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 			"method":     r.Method,
 			"uri":        r.RequestURI,
 			"proto":      r.Proto,
-			"body":       extractRequestBody(r),
+			"body":       RedactBody(extractRequestBody(r)),
 		}
 		logging.LogCustomField(logrus.InfoLevel, field, "Incoming request")
 
@@ -45,7 +48,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		field = logrus.Fields{
 			"request_id": requestID,
 			"status":     responseRecorder.Status(),
-			"body":       responseRecorder.Body(),
+			"body":       RedactBody(responseRecorder.Body()),
 			"duration":   fmt.Sprintf("%v", time.Since(startTime)),
 		}
 
@@ -53,6 +56,57 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// JSON fields that carry credentials, their values never reach the logs: passwords, login and reset tokens,
+// API keys, webhook and TOTP secrets, one-time codes and recovery codes
+var redactedFields = map[string]bool{
+	"password":         true,
+	"old_password":     true,
+	"new_password":     true,
+	"token":            true,
+	"interim_token":    true,
+	"key":              true,
+	"secret":           true,
+	"provisioning_uri": true,
+	"code":             true,
+	"recovery_codes":   true,
+}
+
+const redacted = "[REDACTED]"
+
+// RedactBody replaces the values of credential fields anywhere in a JSON body, other bodies are returned as is
+func RedactBody(body string) string {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+
+	out, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return body
+	}
+	return string(out)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if redactedFields[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return value
+}
+
 type ResponseRecorder struct {
 	http.ResponseWriter
 	status int
@@ -94,3 +148,68 @@ func (r *ResponseRecorder) Status() int {
 func (r *ResponseRecorder) Body() string {
 	return r.body.String()
 }
+
+var trustedProxies []*net.IPNet
+
+// SetTrustedProxies sets the proxies, as IPs or CIDRs, whose X-Forwarded-For header is believed
+func SetTrustedProxies(proxies []string) error {
+	var networks []*net.IPNet
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		networks = append(networks, network)
+	}
+	trustedProxies = networks
+	return nil
+}
+
+func isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetClientIP returns the address of the client. X-Forwarded-For is only read when the request
+// comes from a trusted proxy, walking it from the right until the first hop that is not one
+func GetClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !isTrustedProxy(hop) {
+			return hop
+		}
+		host = hop
+	}
+	return host
+}
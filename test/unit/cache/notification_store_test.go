@@ -0,0 +1,50 @@
+package cache_test
+
+import (
+	"chat-be/internal/cache"
+	"chat-be/internal/delivery/http/models"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryNotificationStoreFlushesInOrder(t *testing.T) {
+	store := cache.NewMemoryNotificationStore()
+	ctx := context.Background()
+
+	assert.Nil(t, store.Push(ctx, models.Notification{ID: "1", UserID: "alice"}))
+	assert.Nil(t, store.Push(ctx, models.Notification{ID: "2", UserID: "bob"}))
+	assert.Nil(t, store.Push(ctx, models.Notification{ID: "3", UserID: "alice"}))
+
+	pending, err := store.Flush(ctx, "alice")
+	assert.Nil(t, err)
+	assert.Len(t, pending, 2)
+	assert.Equal(t, "1", pending[0].ID)
+	assert.Equal(t, "3", pending[1].ID)
+
+	// Flushing removes them, other users keep theirs
+	pending, err = store.Flush(ctx, "alice")
+	assert.Nil(t, err)
+	assert.Empty(t, pending)
+
+	pending, err = store.Flush(ctx, "bob")
+	assert.Nil(t, err)
+	assert.Len(t, pending, 1)
+}
+
+func TestMemoryNotificationStoreDropsOldest(t *testing.T) {
+	store := cache.NewMemoryNotificationStore()
+	ctx := context.Background()
+
+	for i := 0; i < 510; i++ {
+		store.Push(ctx, models.Notification{ID: fmt.Sprint(i), UserID: "alice"})
+	}
+
+	pending, err := store.Flush(ctx, "alice")
+	assert.Nil(t, err)
+	assert.Len(t, pending, 500)
+	assert.Equal(t, "10", pending[0].ID)
+	assert.Equal(t, "509", pending[499].ID)
+}
@@ -0,0 +1,43 @@
+package helper_test
+
+import (
+	"chat-be/package/helper"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailVerificationToken(t *testing.T) {
+	token, err := helper.GenerateEmailVerificationToken("ccffa72d-8a9f-463d-8f76-aa2edbba7b8b", "azis@mail.com", time.Hour)
+	assert.Nil(t, err)
+
+	claims, err := helper.ValidateEmailVerificationToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "azis@mail.com", claims.Email)
+}
+
+func TestEmailVerificationTokenExpired(t *testing.T) {
+	token, err := helper.GenerateEmailVerificationToken("ccffa72d-8a9f-463d-8f76-aa2edbba7b8b", "azis@mail.com", -time.Minute)
+	assert.Nil(t, err)
+
+	_, err = helper.ValidateEmailVerificationToken(token)
+	assert.NotNil(t, err)
+}
+
+func TestEmailVerificationRejectsLoginToken(t *testing.T) {
+	token, err := helper.GenerateToken("ccffa72d-8a9f-463d-8f76-aa2edbba7b8b", "azis@mail.com", "azis", "/ws/a", "5f1c1a9e-93a3-4f0e-b3b0-2f8a1f7f3c11")
+	assert.Nil(t, err)
+
+	_, err = helper.ValidateEmailVerificationToken(token)
+	assert.NotNil(t, err)
+}
+
+func TestLoginRejectsEmailVerificationToken(t *testing.T) {
+	token, err := helper.GenerateEmailVerificationToken("ccffa72d-8a9f-463d-8f76-aa2edbba7b8b", "azis@mail.com", 24*time.Hour)
+	assert.Nil(t, err)
+
+	claims, err := helper.ValidateToken(token)
+	assert.NotNil(t, err)
+	assert.Nil(t, claims)
+}
@@ -0,0 +1,27 @@
+package helper_test
+
+import (
+	"chat-be/package/helper"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLoginToken(t *testing.T) {
+	token, err := helper.GenerateToken("ccffa72d-8a9f-463d-8f76-aa2edbba7b8b", "azis@mail.com", "azis", "/ws/a", "5f1c1a9e-93a3-4f0e-b3b0-2f8a1f7f3c11")
+	assert.Nil(t, err)
+
+	claims, err := helper.ValidateToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "ccffa72d-8a9f-463d-8f76-aa2edbba7b8b", claims.UserID)
+}
+
+func TestValidateTokenRejectsTwoFactorToken(t *testing.T) {
+	token, err := helper.GenerateTwoFactorToken("ccffa72d-8a9f-463d-8f76-aa2edbba7b8b", "azis@mail.com", 5*time.Minute)
+	assert.Nil(t, err)
+
+	claims, err := helper.ValidateToken(token)
+	assert.NotNil(t, err)
+	assert.Nil(t, claims)
+}
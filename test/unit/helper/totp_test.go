@@ -0,0 +1,42 @@
+package helper_test
+
+import (
+	"chat-be/package/helper"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Secret and expected values from the RFC 6238 test vectors, truncated to six digits
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTPCode(t *testing.T) {
+	code, err := helper.GenerateTOTPCode(rfcSecret, time.Unix(59, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "287082", code)
+
+	code, err = helper.GenerateTOTPCode(rfcSecret, time.Unix(1111111109, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "081804", code)
+}
+
+func TestValidateTOTPCodeWithSkew(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	previous, err := helper.GenerateTOTPCode(rfcSecret, now.Add(-30*time.Second))
+	assert.Nil(t, err)
+
+	assert.True(t, helper.ValidateTOTPCode(rfcSecret, previous, now))
+	assert.False(t, helper.ValidateTOTPCode(rfcSecret, previous, now.Add(2*time.Minute)))
+	assert.False(t, helper.ValidateTOTPCode(rfcSecret, "12345", now))
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	secret, err := helper.GenerateTOTPSecret()
+	assert.Nil(t, err)
+
+	uri := helper.TOTPProvisioningURI(secret, "azis@mail.com", "WeTalk")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/WeTalk:azis@mail.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+}
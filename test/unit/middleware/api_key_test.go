@@ -0,0 +1,52 @@
+package middleware_test
+
+import (
+	"chat-be/package/helper"
+	"chat-be/package/middleware"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveWithAPIKey(handler http.Handler, key string) int {
+	middleware.SetAPIKeyAuthenticator(func(key string) (*helper.Claims, error) {
+		if key != "wtk_reader_secret" {
+			return nil, errors.New("invalid API key")
+		}
+		return &helper.Claims{UserID: "bd7c0a3e-5a1f-4c36-9f0e-1a6b2d3c4e5f", APIKeyID: "3b8f2c1d-7e6a-4f5b-9c0d-2e1f3a4b5c6d", Scopes: []string{"messages:read"}}, nil
+	})
+	defer middleware.SetAPIKeyAuthenticator(nil)
+
+	r := httptest.NewRequest("GET", "/api/messages", nil)
+	r.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code
+}
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestAPIKeyWithGrantedScope(t *testing.T) {
+	handler := middleware.AllowAPIKey("messages:read")(middleware.AuthMiddleware(okHandler))
+	assert.Equal(t, http.StatusOK, serveWithAPIKey(handler, "wtk_reader_secret"))
+}
+
+func TestAPIKeyWithoutScope(t *testing.T) {
+	handler := middleware.AllowAPIKey("messages:send")(middleware.AuthMiddleware(okHandler))
+	assert.Equal(t, http.StatusForbidden, serveWithAPIKey(handler, "wtk_reader_secret"))
+}
+
+func TestAPIKeyOnLoginOnlyRoute(t *testing.T) {
+	handler := middleware.AuthMiddleware(okHandler)
+	assert.Equal(t, http.StatusForbidden, serveWithAPIKey(handler, "wtk_reader_secret"))
+}
+
+func TestRevokedAPIKey(t *testing.T) {
+	handler := middleware.AllowAPIKey("messages:read")(middleware.AuthMiddleware(okHandler))
+	assert.Equal(t, http.StatusUnauthorized, serveWithAPIKey(handler, "wtk_revoked_secret"))
+}
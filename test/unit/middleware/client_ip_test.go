@@ -0,0 +1,45 @@
+package middleware_test
+
+import (
+	"chat-be/package/middleware"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIPIgnoresForwardedFromUntrustedPeer(t *testing.T) {
+	assert.Nil(t, middleware.SetTrustedProxies([]string{"10.0.0.0/8"}))
+	defer middleware.SetTrustedProxies(nil)
+
+	r := httptest.NewRequest("POST", "/api/login", nil)
+	r.RemoteAddr = "203.0.113.7:51234"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	assert.Equal(t, "203.0.113.7", middleware.GetClientIP(r))
+}
+
+func TestClientIPTakesRightMostUntrustedHop(t *testing.T) {
+	assert.Nil(t, middleware.SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10"}))
+	defer middleware.SetTrustedProxies(nil)
+
+	r := httptest.NewRequest("POST", "/api/login", nil)
+	r.RemoteAddr = "10.0.0.2:51234"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4, 198.51.100.9, 192.168.1.10")
+
+	assert.Equal(t, "198.51.100.9", middleware.GetClientIP(r))
+}
+
+func TestClientIPWithoutTrustedProxies(t *testing.T) {
+	assert.Nil(t, middleware.SetTrustedProxies(nil))
+
+	r := httptest.NewRequest("POST", "/api/login", nil)
+	r.RemoteAddr = "10.0.0.2:51234"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	assert.Equal(t, "10.0.0.2", middleware.GetClientIP(r))
+}
+
+func TestSetTrustedProxiesRejectsInvalidEntry(t *testing.T) {
+	assert.NotNil(t, middleware.SetTrustedProxies([]string{"not-an-ip"}))
+}
@@ -0,0 +1,28 @@
+package middleware_test
+
+import (
+	"chat-be/package/middleware"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactBodyHidesCredentials(t *testing.T) {
+	// API key, webhook secret, TOTP enrollment and a reset request
+	bodies := map[string]string{
+		`{"data":{"id":"k1","key":"wtk_live_abc"}}`:                                                 `wtk_live_abc`,
+		`{"data":{"id":"w1","secret":"whsec_abc","url":"https://example.com"}}`:                     `whsec_abc`,
+		`{"data":{"secret":"JBSWY3DP","provisioning_uri":"otpauth://totp/WeTalk?secret=JBSWY3DP"}}`: `JBSWY3DP`,
+		`{"data":{"recovery_codes":["aaaa-bbbb","cccc-dddd"]}}`:                                     `aaaa-bbbb`,
+		`{"token":"reset-abc","new_password":"new-secret123"}`:                                      `reset-abc`,
+	}
+	for body, secret := range bodies {
+		redacted := middleware.RedactBody(body)
+		assert.NotContains(t, redacted, secret)
+		assert.Contains(t, redacted, "[REDACTED]")
+	}
+
+	assert.JSONEq(t, `{"message":"ok","data":{"id":"w1","last_status_code":200,"url":"https://example.com"}}`,
+		middleware.RedactBody(`{"message":"ok","data":{"id":"w1","last_status_code":200,"url":"https://example.com"}}`))
+	assert.Equal(t, "not json", middleware.RedactBody("not json"))
+}
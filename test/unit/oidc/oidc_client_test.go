@@ -0,0 +1,145 @@
+package oidc_test
+
+import (
+	"chat-be/internal/oidc"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeIdP is a minimal OpenID provider that issues one authorization code per authorize call
+type fakeIdP struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	idp := &fakeIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test-key",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "test-code" || oidc.CodeChallengeS256(r.Form.Get("code_verifier")) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":                idp.server.URL,
+			"sub":                "employee-42",
+			"aud":                "wetalk",
+			"exp":                time.Now().Add(time.Minute).Unix(),
+			"iat":                time.Now().Unix(),
+			"nonce":              idp.nonce,
+			"email":              "jane@corp.example",
+			"email_verified":     true,
+			"preferred_username": "jane.doe",
+		})
+		token.Header["kid"] = "test-key"
+		signed, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize mimics the user approving the login at the provider
+func (idp *fakeIdP) authorize(t *testing.T, authorizationURL string) {
+	parsed, err := url.Parse(authorizationURL)
+	assert.Nil(t, err)
+	assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+	idp.challenge = parsed.Query().Get("code_challenge")
+	idp.nonce = parsed.Query().Get("nonce")
+}
+
+func TestExchangeWithPKCE(t *testing.T) {
+	idp := newFakeIdP(t)
+	provider := oidc.NewProvider(oidc.Config{IssuerURL: idp.server.URL, ClientID: "wetalk", RedirectURL: "http://localhost:3000/sso/callback"})
+
+	verifier, err := oidc.GenerateCodeVerifier()
+	assert.Nil(t, err)
+	authorizationURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce-1", oidc.CodeChallengeS256(verifier))
+	assert.Nil(t, err)
+	idp.authorize(t, authorizationURL)
+
+	claims, err := provider.Exchange(context.Background(), "test-code", verifier, "nonce-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "employee-42", claims.Subject)
+	assert.Equal(t, "jane@corp.example", claims.Email)
+	assert.True(t, claims.EmailVerified)
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	idp := newFakeIdP(t)
+	provider := oidc.NewProvider(oidc.Config{IssuerURL: idp.server.URL, ClientID: "wetalk"})
+
+	verifier, _ := oidc.GenerateCodeVerifier()
+	authorizationURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce-1", oidc.CodeChallengeS256(verifier))
+	assert.Nil(t, err)
+	idp.authorize(t, authorizationURL)
+
+	_, err = provider.Exchange(context.Background(), "test-code", "another-verifier", "nonce-1")
+	assert.NotNil(t, err)
+}
+
+func TestExchangeRejectsNonceMismatch(t *testing.T) {
+	idp := newFakeIdP(t)
+	provider := oidc.NewProvider(oidc.Config{IssuerURL: idp.server.URL, ClientID: "wetalk"})
+
+	verifier, _ := oidc.GenerateCodeVerifier()
+	authorizationURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce-1", oidc.CodeChallengeS256(verifier))
+	assert.Nil(t, err)
+	idp.authorize(t, authorizationURL)
+
+	_, err = provider.Exchange(context.Background(), "test-code", verifier, "nonce-2")
+	assert.NotNil(t, err)
+}
+
+func TestExchangeRejectsWrongAudience(t *testing.T) {
+	idp := newFakeIdP(t)
+	provider := oidc.NewProvider(oidc.Config{IssuerURL: idp.server.URL, ClientID: "another-client"})
+
+	verifier, _ := oidc.GenerateCodeVerifier()
+	authorizationURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce-1", oidc.CodeChallengeS256(verifier))
+	assert.Nil(t, err)
+	idp.authorize(t, authorizationURL)
+
+	_, err = provider.Exchange(context.Background(), "test-code", verifier, "nonce-1")
+	assert.NotNil(t, err)
+}
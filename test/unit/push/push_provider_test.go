@@ -0,0 +1,129 @@
+package push_test
+
+import (
+	"chat-be/internal/push"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFCMServer(t *testing.T, invalidToken string) (*httptest.Server, *int) {
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			assert.Nil(t, r.ParseForm())
+			assert.NotEmpty(t, r.Form.Get("assertion"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access-1", "expires_in": 3600})
+		case "/v1/projects/wetalk/messages:send":
+			assert.Equal(t, "Bearer access-1", r.Header.Get("Authorization"))
+			var body struct {
+				Message struct {
+					Token string `json:"token"`
+				} `json:"message"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Message.Token == "malformed-payload" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":{"status":"INVALID_ARGUMENT","details":[{"errorCode":"INVALID_ARGUMENT"}]}}`))
+				return
+			}
+			if body.Message.Token == invalidToken {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+				return
+			}
+			w.Write([]byte(`{"name":"projects/wetalk/messages/1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, &tokenRequests
+}
+
+func TestFCMProviderSendsAndDetectsInvalidTokens(t *testing.T) {
+	server, tokenRequests := newFCMServer(t, "stale-token")
+	defer server.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	provider := push.NewFCMProvider(push.FCMConfig{
+		ProjectID:   "wetalk",
+		ClientEmail: "push@wetalk.iam.gserviceaccount.com",
+		PrivateKey:  string(keyPEM),
+		TokenURI:    server.URL + "/token",
+		BaseURL:     server.URL,
+	})
+
+	ctx := context.Background()
+	assert.Nil(t, provider.Send(ctx, push.Message{Token: "good-token", Title: "alice", Body: "hi"}))
+	assert.Nil(t, provider.Send(ctx, push.Message{Token: "good-token", Title: "alice", Body: "again"}))
+	assert.Equal(t, 1, *tokenRequests, "access token should be cached")
+
+	assert.ErrorIs(t, provider.Send(ctx, push.Message{Token: "stale-token"}), push.ErrInvalidToken)
+
+	// A rejected request is not proof the token is dead, it must not be deleted
+	err = provider.Send(ctx, push.Message{Token: "malformed-payload"})
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, push.ErrInvalidToken)
+}
+
+func TestAPNsProviderSendsAndDetectsInvalidTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "bearer "))
+		assert.Equal(t, "com.wetalk.app", r.Header.Get("apns-topic"))
+		switch r.URL.Path {
+		case "/3/device/good-token":
+			w.WriteHeader(http.StatusOK)
+		case "/3/device/stale-token":
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"reason":"Unregistered"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"reason":"BadDeviceToken"}`))
+		}
+	}))
+	defer server.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	provider, err := push.NewAPNsProvider(push.APNsConfig{
+		KeyID:   "KEY123",
+		TeamID:  "TEAM123",
+		Topic:   "com.wetalk.app",
+		BaseURL: server.URL,
+	}, keyPEM)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+	assert.Nil(t, provider.Send(ctx, push.Message{Token: "good-token", Title: "alice", Body: "hi"}))
+	assert.ErrorIs(t, provider.Send(ctx, push.Message{Token: "stale-token"}), push.ErrInvalidToken)
+	assert.ErrorIs(t, provider.Send(ctx, push.Message{Token: "garbage"}), push.ErrInvalidToken)
+}
+
+func TestFakeProviderRejectsInvalidTokens(t *testing.T) {
+	provider := push.NewFakeProvider()
+	provider.Invalid["stale-token"] = true
+
+	assert.Nil(t, provider.Send(context.Background(), push.Message{Token: "good-token"}))
+	assert.ErrorIs(t, provider.Send(context.Background(), push.Message{Token: "stale-token"}), push.ErrInvalidToken)
+	assert.Len(t, provider.Messages(), 1)
+}
@@ -1,14 +1,59 @@
 package repository_test
 
 import (
-	"fmt"
+	"strings"
 	"testing"
 
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/domain/repositories"
+
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
+// newSearchUser stores a user with an exact username, bypassing registration
+func newSearchUser(t *testing.T, socketID, username string, discoverable bool) *entities.User {
+	user := &entities.User{
+		ID:       uuid.New().String(),
+		Username: username,
+		Email:    username + "@mail.com",
+		Password: "not-a-hash",
+		SocketID: socketID,
+	}
+	assert.Nil(t, userRepo.Create(user))
+
+	// Discoverable has a database default, a false value is only written by an update
+	user.Discoverable = discoverable
+	assert.Nil(t, userRepo.Update(user))
+	return user
+}
+
+func usernames(users []entities.User) []string {
+	var names []string
+	for _, user := range users {
+		names = append(names, user.Username)
+	}
+	return names
+}
+
 func TestGetUser(t *testing.T) {
-	users, err := userRepo.SearchByUsernameOrEmail("azis")
+	socketPath := &entities.SocketPath{ID: uuid.New().String(), Path: "/ws/" + uuid.New().String()}
+	assert.Nil(t, socketPathRepo.Create(socketPath))
+
+	base := "srch" + strings.ReplaceAll(uuid.New().String(), "-", "")[:10]
+	searcher := newSearchUser(t, socketPath.ID, base+"_searcher", true)
+	trigram := newSearchUser(t, socketPath.ID, "x"+base, true)
+	prefix := newSearchUser(t, socketPath.ID, base+"_b", true)
+	exact := newSearchUser(t, socketPath.ID, base, true)
+	hidden := newSearchUser(t, socketPath.ID, base+"_a", false)
+
+	users, total, err := userRepo.SearchUsers(repositories.UserSearchQuery{Text: base, SearcherID: searcher.ID, Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []string{exact.Username, prefix.Username, trigram.Username}, usernames(users))
+
+	// A user who is not discoverable is still found by their exact username
+	users, _, err = userRepo.SearchUsers(repositories.UserSearchQuery{Text: hidden.Username, SearcherID: searcher.ID, Limit: 10})
 	assert.Nil(t, err)
-	fmt.Println(users)
+	assert.Equal(t, hidden.Username, users[0].Username)
 }
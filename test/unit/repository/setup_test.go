@@ -13,9 +13,10 @@ import (
 )
 
 var (
-	userRepo     repositories.UserRepository
-	chatRoomRepo repositories.ChatRoomRepository
-	ctx          context.Context
+	userRepo       repositories.UserRepository
+	chatRoomRepo   repositories.ChatRoomRepository
+	socketPathRepo repositories.SocketPathRepository
+	ctx            context.Context
 )
 
 func TestMain(m *testing.M) {
@@ -33,6 +34,7 @@ func setup() {
 
 	chatRoomRepo = repositories.NewChatRoomRepository(db)
 	userRepo = repositories.NewUserRepository(db)
+	socketPathRepo = repositories.NewSocketPathRepository(db)
 
 	requestID := uuid.New().String()
 	ctx = context.WithValue(context.Background(), logging.RequestIDKey, requestID)
@@ -0,0 +1,70 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/usecases"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestBot(t *testing.T, owner *entities.User) *entities.UserResponse {
+	bot, err := botUsecase.CreateBot(owner.ID, models.CreateBotRequest{Username: "bot_" + uuid.New().String()[:8]})
+	assert.Nil(t, err)
+	return bot
+}
+
+func TestCreateBotIsVerified(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	bot := createTestBot(t, owner)
+	assert.True(t, bot.EmailVerified)
+	assert.True(t, bot.IsBot)
+}
+
+func TestBotCanMessageWhenVerificationRequired(t *testing.T) {
+	t.Setenv("EMAIL_VERIFICATION_REQUIRED", usecases.EmailVerificationMessaging)
+	owner := newTestUser(t, "owner")
+	bot := createTestBot(t, owner)
+
+	room, err := chatRoomUsecase.CreateRoom(owner.ID, []string{owner.ID, bot.ID}, false, "")
+	assert.Nil(t, err)
+
+	err = messageUsecase.SaveMessage(&entities.Message{ID: uuid.New().String(), ChatRoomID: room.ID, SenderID: bot.ID, Content: "Hello from a bot"})
+	assert.Nil(t, err)
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	bot := createTestBot(t, owner)
+
+	apiKey, err := botUsecase.CreateAPIKey(owner.ID, bot.ID, models.CreateAPIKeyRequest{Name: "reader", Scopes: []string{entities.ScopeMessagesRead}})
+	assert.Nil(t, err)
+
+	claims, err := botUsecase.AuthenticateAPIKey(apiKey.Key)
+	assert.Nil(t, err)
+	assert.Equal(t, bot.ID, claims.UserID)
+	assert.True(t, claims.HasScope(entities.ScopeMessagesRead))
+	assert.False(t, claims.HasScope(entities.ScopeMessagesSend))
+}
+
+func TestRevokedAPIKeyRejected(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	bot := createTestBot(t, owner)
+
+	apiKey, err := botUsecase.CreateAPIKey(owner.ID, bot.ID, models.CreateAPIKeyRequest{Name: "sender", Scopes: []string{entities.ScopeMessagesSend}})
+	assert.Nil(t, err)
+
+	// Only the owner of the bot can revoke its keys
+	other := newTestUser(t, "other")
+	err = botUsecase.RevokeAPIKey(other.ID, bot.ID, apiKey.ID)
+	assert.NotNil(t, err)
+
+	err = botUsecase.RevokeAPIKey(owner.ID, bot.ID, apiKey.ID)
+	assert.Nil(t, err)
+
+	claims, err := botUsecase.AuthenticateAPIKey(apiKey.Key)
+	assert.Equal(t, usecases.ErrInvalidAPIKey, err)
+	assert.Nil(t, claims)
+}
@@ -0,0 +1,80 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/usecases"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func postMessage(roomID, senderID, content string) error {
+	return messageUsecase.SaveMessage(&entities.Message{ID: uuid.New().String(), ChatRoomID: roomID, SenderID: senderID, Content: content})
+}
+
+func TestOnlyAdminsPostInChannel(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	admin := newTestUser(t, "admin")
+	subscriber := newTestUser(t, "subscriber")
+
+	channel, err := chatRoomUsecase.CreateChannel(owner.ID, models.CreateChannelRequest{Name: "Announcements"})
+	assert.Nil(t, err)
+	assert.True(t, channel.IsChannel)
+
+	for _, v := range []*entities.User{admin, subscriber} {
+		_, err = chatRoomUsecase.Subscribe(v.ID, channel.ID)
+		assert.Nil(t, err)
+	}
+	err = chatRoomUsecase.ChangeRole(owner.ID, channel.ID, admin.ID, entities.RoleAdmin)
+	assert.Nil(t, err)
+
+	assert.Nil(t, postMessage(channel.ID, owner.ID, "Welcome"))
+	assert.Nil(t, postMessage(channel.ID, admin.ID, "Schedule is out"))
+	assert.Equal(t, usecases.ErrChannelReadOnly, postMessage(channel.ID, subscriber.ID, "Hello?"))
+}
+
+func TestSubscribePrivateChannel(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	subscriber := newTestUser(t, "subscriber")
+
+	channel, err := chatRoomUsecase.CreateChannel(owner.ID, models.CreateChannelRequest{Name: "Staff only", IsPrivate: true})
+	assert.Nil(t, err)
+
+	_, err = chatRoomUsecase.Subscribe(subscriber.ID, channel.ID)
+	assert.NotNil(t, err)
+	assert.Equal(t, "", roleOf(t, channel.ID, subscriber.ID))
+}
+
+func TestChannelPostReceiptsWrittenByFanOut(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	channel, err := chatRoomUsecase.CreateChannel(owner.ID, models.CreateChannelRequest{Name: "Releases"})
+	assert.Nil(t, err)
+
+	var subscribers []string
+	for i := 0; i < 3; i++ {
+		subscriber := newTestUser(t, "subscriber")
+		_, err = chatRoomUsecase.Subscribe(subscriber.ID, channel.ID)
+		assert.Nil(t, err)
+		subscribers = append(subscribers, subscriber.ID)
+	}
+
+	post := &entities.Message{ID: uuid.New().String(), ChatRoomID: channel.ID, SenderID: owner.ID, Content: "v2 is out"}
+	assert.Nil(t, messageUsecase.SaveMessage(post))
+
+	// The post is stored right away, its receipts wait for the worker
+	var fanOut entities.ChannelFanOut
+	assert.Nil(t, db.Where("message_id = ?", post.ID).First(&fanOut).Error)
+	assert.Equal(t, entities.ChannelFanOutPending, fanOut.Status)
+
+	assert.Nil(t, messageUsecase.ProcessChannelFanOuts())
+
+	var receiverIDs []string
+	err = db.Model(&entities.MessageStatus{}).Where("message_id = ?", post.ID).Pluck("receiver_id", &receiverIDs).Error
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, subscribers, receiverIDs)
+
+	assert.Nil(t, db.Where("message_id = ?", post.ID).First(&fanOut).Error)
+	assert.Equal(t, entities.ChannelFanOutDone, fanOut.Status)
+}
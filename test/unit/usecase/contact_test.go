@@ -0,0 +1,76 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptFriendRequest(t *testing.T) {
+	sender := newTestUser(t, "sender")
+	receiver := newTestUser(t, "receiver")
+
+	request, err := contactUsecase.SendRequest(sender.ID, receiver.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, entities.ContactPending, request.Status)
+
+	incoming, err := contactUsecase.GetRequests(receiver.ID)
+	assert.Nil(t, err)
+	assert.Len(t, incoming, 1)
+	assert.Equal(t, usecases.FriendRequestIncoming, incoming[0].Direction)
+
+	// Only the addressee can answer
+	_, err = contactUsecase.RespondRequest(sender.ID, request.ID, true)
+	assert.Equal(t, usecases.ErrFriendRequestNotFound, err)
+
+	accepted, err := contactUsecase.RespondRequest(receiver.ID, request.ID, true)
+	assert.Nil(t, err)
+	assert.Equal(t, entities.ContactAccepted, accepted.Status)
+
+	contacts, err := contactUsecase.GetContacts(sender.ID)
+	assert.Nil(t, err)
+	assert.Len(t, contacts, 1)
+	assert.Equal(t, receiver.ID, contacts[0].UserID)
+}
+
+func TestDeclineFriendRequest(t *testing.T) {
+	sender := newTestUser(t, "sender")
+	receiver := newTestUser(t, "receiver")
+
+	request, err := contactUsecase.SendRequest(sender.ID, receiver.ID)
+	assert.Nil(t, err)
+
+	declined, err := contactUsecase.RespondRequest(receiver.ID, request.ID, false)
+	assert.Nil(t, err)
+	assert.Equal(t, entities.ContactDeclined, declined.Status)
+
+	_, err = contactUsecase.RespondRequest(receiver.ID, request.ID, true)
+	assert.NotNil(t, err)
+
+	contacts, err := contactUsecase.GetContacts(sender.ID)
+	assert.Nil(t, err)
+	assert.Len(t, contacts, 0)
+}
+
+func TestContactsOnlyDirectRoom(t *testing.T) {
+	sender := newTestUser(t, "sender")
+	receiver := newTestUser(t, "receiver")
+
+	contactsOnly := true
+	_, err := userUsecase.UpdatePrivacySettings(receiver.ID, models.PrivacySettingsRequest{ContactsOnly: &contactsOnly})
+	assert.Nil(t, err)
+
+	_, err = chatRoomUsecase.CreateRoom(sender.ID, []string{sender.ID, receiver.ID}, false, "")
+	assert.Equal(t, usecases.ErrContactRequired, err)
+
+	request, err := contactUsecase.SendRequest(sender.ID, receiver.ID)
+	assert.Nil(t, err)
+	_, err = contactUsecase.RespondRequest(receiver.ID, request.ID, true)
+	assert.Nil(t, err)
+
+	_, err = chatRoomUsecase.CreateRoom(sender.ID, []string{sender.ID, receiver.ID}, false, "")
+	assert.Nil(t, err)
+}
@@ -0,0 +1,32 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"chat-be/internal/domain/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResendVerificationDoesNotRevealAccount(t *testing.T) {
+	m := &recordingMailer{}
+	usecase := newUserUsecaseWithMailer(m)
+	suffix := uuid.New().String()[:8]
+	user := &entities.User{Username: "verify_" + suffix, Email: "verify_" + suffix + "@mail.com", Password: testPassword}
+	assert.Nil(t, usecase.Register(user))
+	assert.NotEmpty(t, m.body)
+
+	// Sent moments ago, the resend is skipped without telling the caller
+	m.body = ""
+	assert.Nil(t, usecase.ResendVerification(user.Email))
+	assert.Empty(t, m.body)
+
+	err := db.Model(&entities.User{}).Where("id = ?", user.ID).Update("verification_sent_at", nil).Error
+	assert.Nil(t, err)
+	m.err = errors.New("smtp unavailable")
+	assert.Nil(t, usecase.ResendVerification(user.Email))
+
+	assert.Nil(t, usecase.ResendVerification("nobody@mail.com"))
+}
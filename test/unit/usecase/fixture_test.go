@@ -0,0 +1,30 @@
+package usecase_test
+
+import (
+	"chat-be/internal/domain/entities"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPassword = "secret123"
+
+// newTestUser registers a verified user with a unique username and email
+func newTestUser(t *testing.T, name string) *entities.User {
+	suffix := uuid.New().String()[:8]
+	user := &entities.User{
+		Username: name + "_" + suffix,
+		Email:    name + "_" + suffix + "@mail.com",
+		Password: testPassword,
+	}
+	err := userUsecase.Register(user)
+	assert.Nil(t, err)
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	err = userRepo.Update(user)
+	assert.Nil(t, err)
+	return user
+}
@@ -1,14 +1,55 @@
 package usecase_test
 
 import (
-	"fmt"
+	"strings"
 	"testing"
 
+	"chat-be/internal/domain/entities"
+
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
+// newSearchUser registers a user with an exact username so search ranking can be asserted
+func newSearchUser(t *testing.T, username string, discoverable bool) *entities.User {
+	user := &entities.User{Username: username, Email: username + "@mail.com", Password: testPassword}
+	assert.Nil(t, userUsecase.Register(user))
+
+	// Discoverable has a database default, a false value is only written by an update
+	user.Discoverable = discoverable
+	assert.Nil(t, userRepo.Update(user))
+	return user
+}
+
 func TestGetUser(t *testing.T) {
-	users, err := userRepo.SearchByUsernameOrEmail("azis")
+	base := "srch" + strings.ReplaceAll(uuid.New().String(), "-", "")[:10]
+	searcher := newTestUser(t, "searcher")
+	trigram := newSearchUser(t, "x"+base, true)
+	prefix := newSearchUser(t, base+"_b", true)
+	exact := newSearchUser(t, base, true)
+	hidden := newSearchUser(t, base+"_a", false)
+
+	users, total, err := userUsecase.SearchUsers("@"+base, searcher.ID, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	var names []string
+	for _, user := range users {
+		names = append(names, user.Username)
+		assert.Empty(t, user.Email)
+	}
+	assert.Equal(t, []string{exact.Username, prefix.Username, trigram.Username}, names)
+
+	// An exact email finds a hidden user and is the only case where the email is returned
+	users, _, err = userUsecase.SearchUsers(strings.ToUpper(hidden.Email), searcher.ID, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, hidden.ID, users[0].ID)
+	assert.Equal(t, hidden.Email, users[0].Email)
+	for _, user := range users[1:] {
+		assert.Empty(t, user.Email)
+	}
+
+	users, _, err = userUsecase.SearchUsers(hidden.Username, searcher.ID, 1, 10)
 	assert.Nil(t, err)
-	fmt.Println(users)
+	assert.Equal(t, hidden.ID, users[0].ID)
+	assert.Empty(t, users[0].Email)
 }
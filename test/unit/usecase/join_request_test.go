@@ -0,0 +1,87 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPrivateGroup(t *testing.T, owner, admin *entities.User, members ...*entities.User) string {
+	roomID := newTestGroup(t, owner, admin, members...)
+	isPrivate := true
+	_, err := chatRoomUsecase.UpdateRoom(owner.ID, roomID, models.UpdateRoomRequest{IsPrivate: &isPrivate})
+	assert.Nil(t, err)
+	return roomID
+}
+
+func TestApproveJoinRequest(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	admin := newTestUser(t, "admin")
+	requester := newTestUser(t, "requester")
+	roomID := newPrivateGroup(t, owner, admin)
+
+	joinRequest, err := chatRoomUsecase.RequestToJoin(requester.ID, roomID, models.CreateJoinRequestRequest{Message: "Let me in"})
+	assert.Nil(t, err)
+	assert.Equal(t, entities.JoinRequestPending, joinRequest.Status)
+
+	pending, err := chatRoomUsecase.GetJoinRequests(admin.ID, roomID)
+	assert.Nil(t, err)
+	assert.Len(t, pending, 1)
+
+	decided, err := chatRoomUsecase.DecideJoinRequest(admin.ID, roomID, joinRequest.ID, true)
+	assert.Nil(t, err)
+	assert.Equal(t, entities.JoinRequestApproved, decided.Status)
+	assert.Equal(t, admin.ID, decided.DecidedBy)
+	assert.Equal(t, entities.RoleMember, roleOf(t, roomID, requester.ID))
+
+	// A request can only be decided once
+	_, err = chatRoomUsecase.DecideJoinRequest(owner.ID, roomID, joinRequest.ID, false)
+	assert.NotNil(t, err)
+}
+
+func TestRejectJoinRequest(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+	requester := newTestUser(t, "requester")
+	roomID := newPrivateGroup(t, owner, nil, member)
+
+	joinRequest, err := chatRoomUsecase.RequestToJoin(requester.ID, roomID, models.CreateJoinRequestRequest{})
+	assert.Nil(t, err)
+
+	// Repeated requests return the pending one
+	again, err := chatRoomUsecase.RequestToJoin(requester.ID, roomID, models.CreateJoinRequestRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, joinRequest.ID, again.ID)
+
+	decided, err := chatRoomUsecase.DecideJoinRequest(owner.ID, roomID, joinRequest.ID, false)
+	assert.Nil(t, err)
+	assert.Equal(t, entities.JoinRequestRejected, decided.Status)
+	assert.Equal(t, "", roleOf(t, roomID, requester.ID))
+}
+
+func TestMemberCannotDecideJoinRequest(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+	requester := newTestUser(t, "requester")
+	roomID := newPrivateGroup(t, owner, nil, member)
+
+	joinRequest, err := chatRoomUsecase.RequestToJoin(requester.ID, roomID, models.CreateJoinRequestRequest{})
+	assert.Nil(t, err)
+
+	_, err = chatRoomUsecase.DecideJoinRequest(member.ID, roomID, joinRequest.ID, true)
+	assert.Equal(t, usecases.ErrRoomPermission, err)
+	assert.Equal(t, "", roleOf(t, roomID, requester.ID))
+}
+
+func TestJoinRequestForOpenGroup(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+	requester := newTestUser(t, "requester")
+	roomID := newTestGroup(t, owner, nil, member)
+
+	_, err := chatRoomUsecase.RequestToJoin(requester.ID, roomID, models.CreateJoinRequestRequest{})
+	assert.Equal(t, usecases.ErrJoinNotRequired, err)
+}
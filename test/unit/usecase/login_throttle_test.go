@@ -0,0 +1,86 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func failLogin(t *testing.T, email string, times int) {
+	for i := 0; i < times; i++ {
+		_, err := userUsecase.Login(email, "wrong-password", models.ClientInfo{})
+		assert.Equal(t, usecases.ErrInvalidCredentials, err)
+	}
+}
+
+func TestLoginLockedAtThreshold(t *testing.T) {
+	user := newTestUser(t, "throttle")
+
+	failLogin(t, user.Email, 4)
+	attempt, err := loginAttemptRepo.FindByKey("email:" + user.Email)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, attempt.FailedCount)
+	assert.Nil(t, attempt.LockedUntil)
+
+	failLogin(t, user.Email, 1)
+	attempt, err = loginAttemptRepo.FindByKey("email:" + user.Email)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, attempt.FailedCount)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), *attempt.LockedUntil, 5*time.Second)
+
+	// Even the right password is refused while locked
+	_, err = userUsecase.Login(user.Email, testPassword, models.ClientInfo{})
+	assert.Equal(t, usecases.ErrLoginLocked, err)
+}
+
+func TestLoginLockoutBacksOffExponentially(t *testing.T) {
+	user := newTestUser(t, "throttle")
+	key := "email:" + user.Email
+
+	failLogin(t, user.Email, 5)
+	for _, expected := range []time.Duration{time.Minute, 2 * time.Minute} {
+		// Let the previous lock run out so the next failure is counted
+		err := db.Model(&entities.LoginAttempt{}).Where("key = ?", key).Update("locked_until", time.Now().Add(-time.Second)).Error
+		assert.Nil(t, err)
+
+		failLogin(t, user.Email, 1)
+		attempt, err := loginAttemptRepo.FindByKey(key)
+		assert.Nil(t, err)
+		assert.WithinDuration(t, time.Now().Add(expected), *attempt.LockedUntil, 5*time.Second)
+	}
+}
+
+func TestLoginFailuresResetAfterWindow(t *testing.T) {
+	user := newTestUser(t, "throttle")
+	key := "email:" + user.Email
+
+	failLogin(t, user.Email, 5)
+	err := db.Model(&entities.LoginAttempt{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"last_failed_at": time.Now().Add(-2 * time.Hour),
+		"locked_until":   time.Now().Add(-time.Hour),
+	}).Error
+	assert.Nil(t, err)
+
+	failLogin(t, user.Email, 1)
+	attempt, err := loginAttemptRepo.FindByKey(key)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, attempt.FailedCount)
+	assert.Nil(t, attempt.LockedUntil)
+}
+
+func TestLoginSuccessResetsFailures(t *testing.T) {
+	user := newTestUser(t, "throttle")
+
+	failLogin(t, user.Email, 3)
+	response, err := userUsecase.Login(user.Email, testPassword, models.ClientInfo{})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, response.Token)
+
+	attempt, err := loginAttemptRepo.FindByKey("email:" + user.Email)
+	assert.Nil(t, err)
+	assert.Nil(t, attempt)
+}
@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"chat-be/internal/usecases"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func searchCursor(raw string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func TestSearchRejectsInvalidCursor(t *testing.T) {
+	user := newTestUser(t, "searcher")
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+
+	cursors := []string{
+		"not base64!",
+		searchCursor(now),
+		searchCursor("yesterday|" + uuid.New().String()),
+		searchCursor(now + "|"),
+		searchCursor(now + "|not-a-uuid"),
+	}
+	for _, cursor := range cursors {
+		_, _, err := messageUsecase.SearchMessages(user.ID, "hello", "", cursor, 10)
+		assert.ErrorIs(t, err, usecases.ErrInvalidCursor, cursor)
+	}
+}
+
+func TestSearchPagesWithCursor(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+	roomID := newTestGroup(t, owner, nil, member)
+
+	word := "quince" + uuid.New().String()[:8]
+	for _, content := range []string{"first " + word, "second " + word, "third " + word} {
+		assert.Nil(t, postMessage(roomID, owner.ID, content))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Exactly a page left still reports a next page only when a further result exists
+	page, cursor, err := messageUsecase.SearchMessages(member.ID, word, roomID, "", 2)
+	assert.Nil(t, err)
+	assert.Len(t, page, 2)
+	assert.Equal(t, "third "+word, page[0].Text)
+	assert.NotEmpty(t, cursor)
+
+	page, cursor, err = messageUsecase.SearchMessages(member.ID, word, roomID, cursor, 2)
+	assert.Nil(t, err)
+	assert.Len(t, page, 1)
+	assert.Equal(t, "first "+word, page[0].Text)
+	assert.Empty(t, cursor)
+
+	page, cursor, err = messageUsecase.SearchMessages(member.ID, word, roomID, "", 3)
+	assert.Nil(t, err)
+	assert.Len(t, page, 3)
+	assert.Empty(t, cursor)
+}
+
+func TestSearchSnippetEscapesMessageHTML(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	roomID := newTestGroup(t, owner, nil, newTestUser(t, "member"))
+
+	word := "damson" + uuid.New().String()[:8]
+	assert.Nil(t, postMessage(roomID, owner.ID, `<script>alert(1)</script> `+word))
+
+	results, _, err := messageUsecase.SearchMessages(owner.ID, word, roomID, "", 10)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.NotContains(t, results[0].Snippet, "<script>")
+	assert.Contains(t, results[0].Snippet, "&lt;script&gt;")
+	assert.Contains(t, results[0].Snippet, "<mark>"+word+"</mark>")
+}
@@ -0,0 +1,160 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/usecases"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockedUserMessagesHidden(t *testing.T) {
+	viewer := newTestUser(t, "viewer")
+	blocked := newTestUser(t, "blocked")
+	other := newTestUser(t, "other")
+	roomID := newTestGroup(t, viewer, nil, blocked, other)
+
+	assert.Nil(t, postMessage(roomID, blocked.ID, "Spam"))
+	assert.Nil(t, postMessage(roomID, other.ID, "Hello"))
+	assert.Nil(t, moderationUsecase.BlockUser(viewer.ID, blocked.ID))
+
+	messages, _, err := messageUsecase.GetMessageHistory(viewer.ID, roomID, 50, 1)
+	assert.Nil(t, err)
+	var texts []string
+	for _, v := range messages {
+		if v.Type != entities.MessageTypeSystem {
+			texts = append(texts, v.Text)
+		}
+	}
+	assert.Equal(t, []string{"Hello"}, texts)
+
+	// Other members still see both
+	messages, _, err = messageUsecase.GetMessageHistory(other.ID, roomID, 50, 1)
+	assert.Nil(t, err)
+	texts = nil
+	for _, v := range messages {
+		if v.Type != entities.MessageTypeSystem {
+			texts = append(texts, v.Text)
+		}
+	}
+	assert.ElementsMatch(t, []string{"Spam", "Hello"}, texts)
+}
+
+func TestBlockerHiddenFromSearch(t *testing.T) {
+	searcher := newTestUser(t, "searcher")
+	blocker := newTestUser(t, "blocker")
+
+	users, _, err := userUsecase.SearchUsers(blocker.Username, searcher.ID, 1, 10)
+	assert.Nil(t, err)
+	assert.Len(t, users, 1)
+
+	assert.Nil(t, moderationUsecase.BlockUser(blocker.ID, searcher.ID))
+	users, _, err = userUsecase.SearchUsers(blocker.Username, searcher.ID, 1, 10)
+	assert.Nil(t, err)
+	assert.Len(t, users, 0)
+}
+
+func TestBlockedUsersCannotConnect(t *testing.T) {
+	blocker := newTestUser(t, "blocker")
+	blocked := newTestUser(t, "blocked")
+	assert.Nil(t, moderationUsecase.BlockUser(blocker.ID, blocked.ID))
+
+	// Either side is refused
+	_, err := chatRoomUsecase.CreateRoom(blocked.ID, []string{blocked.ID, blocker.ID}, false, "")
+	assert.Equal(t, usecases.ErrUserBlocked, err)
+	_, err = chatRoomUsecase.CreateRoom(blocker.ID, []string{blocker.ID, blocked.ID}, false, "")
+	assert.Equal(t, usecases.ErrUserBlocked, err)
+
+	_, err = contactUsecase.SendRequest(blocked.ID, blocker.ID)
+	assert.NotNil(t, err)
+	_, err = contactUsecase.SendRequest(blocker.ID, blocked.ID)
+	assert.NotNil(t, err)
+}
+
+func TestBlockStopsDirectMessages(t *testing.T) {
+	blocker := newTestUser(t, "blocker")
+	blocked := newTestUser(t, "blocked")
+	room, err := chatRoomUsecase.CreateRoom(blocker.ID, []string{blocker.ID, blocked.ID}, false, "")
+	assert.Nil(t, err)
+
+	assert.Nil(t, moderationUsecase.BlockUser(blocker.ID, blocked.ID))
+	assert.Equal(t, usecases.ErrUserBlocked, postMessage(room.ID, blocked.ID, "Why?"))
+
+	assert.Nil(t, moderationUsecase.UnblockUser(blocker.ID, blocked.ID))
+	assert.Nil(t, postMessage(room.ID, blocked.ID, "Thanks"))
+}
+
+func TestReportAttachesEvidence(t *testing.T) {
+	reporter := newTestUser(t, "reporter")
+	reported := newTestUser(t, "reported")
+	room, err := chatRoomUsecase.CreateRoom(reporter.ID, []string{reporter.ID, reported.ID}, false, "")
+	assert.Nil(t, err)
+
+	message := &entities.Message{ID: uuid.New().String(), ChatRoomID: room.ID, SenderID: reported.ID, Content: "Buy cheap followers"}
+	assert.Nil(t, messageUsecase.SaveMessage(message))
+
+	report, err := moderationUsecase.ReportUser(reporter.ID, models.CreateReportRequest{
+		UserID:     reported.ID,
+		RoomID:     room.ID,
+		Reason:     "spam",
+		MessageIDs: []string{message.ID, message.ID},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.EvidenceCount)
+
+	var evidence []entities.ReportEvidence
+	err = db.Where("report_id = ?", report.ID).Find(&evidence).Error
+	assert.Nil(t, err)
+	assert.Len(t, evidence, 1)
+	assert.Equal(t, message.ID, evidence[0].MessageID)
+	assert.Equal(t, "Buy cheap followers", evidence[0].Content)
+}
+
+func TestReportRejectsOtherUsersMessages(t *testing.T) {
+	reporter := newTestUser(t, "reporter")
+	reported := newTestUser(t, "reported")
+	room, err := chatRoomUsecase.CreateRoom(reporter.ID, []string{reporter.ID, reported.ID}, false, "")
+	assert.Nil(t, err)
+
+	// Reporters cannot pass their own message off as evidence against someone else
+	message := &entities.Message{ID: uuid.New().String(), ChatRoomID: room.ID, SenderID: reporter.ID, Content: "Hi"}
+	assert.Nil(t, messageUsecase.SaveMessage(message))
+
+	_, err = moderationUsecase.ReportUser(reporter.ID, models.CreateReportRequest{UserID: reported.ID, Reason: "harassment", MessageIDs: []string{message.ID}})
+	assert.NotNil(t, err)
+}
+
+func TestBlockedUserMessagesHiddenFromSearchAndPins(t *testing.T) {
+	viewer := newTestUser(t, "viewer")
+	blocked := newTestUser(t, "blocked")
+	other := newTestUser(t, "other")
+	roomID := newTestGroup(t, viewer, nil, blocked, other)
+
+	word := "marmalade" + uuid.New().String()[:8]
+	spam := &entities.Message{ID: uuid.New().String(), ChatRoomID: roomID, SenderID: blocked.ID, Content: "Cheap " + word}
+	assert.Nil(t, messageUsecase.SaveMessage(spam))
+	assert.Nil(t, postMessage(roomID, other.ID, "I like "+word))
+	_, err := chatRoomUsecase.PinMessage(viewer.ID, roomID, spam.ID)
+	assert.Nil(t, err)
+
+	assert.Nil(t, moderationUsecase.BlockUser(viewer.ID, blocked.ID))
+
+	results, _, err := messageUsecase.SearchMessages(viewer.ID, word, "", "", 20)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, other.ID, results[0].SenderID)
+
+	pins, err := chatRoomUsecase.GetPinnedMessages(viewer.ID, roomID)
+	assert.Nil(t, err)
+	assert.Len(t, pins, 0)
+
+	// Other members still find and see the message
+	results, _, err = messageUsecase.SearchMessages(other.ID, word, "", "", 20)
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+	pins, err = chatRoomUsecase.GetPinnedMessages(other.ID, roomID)
+	assert.Nil(t, err)
+	assert.Len(t, pins, 1)
+}
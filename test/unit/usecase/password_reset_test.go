@@ -0,0 +1,79 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/domain/repositories"
+	"chat-be/internal/usecases"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingMailer keeps the last email instead of sending it, or fails when err is set
+type recordingMailer struct {
+	body string
+	err  error
+}
+
+func (m *recordingMailer) Send(to, subject, body string) error {
+	m.body = body
+	return m.err
+}
+
+var resetTokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+func newUserUsecaseWithMailer(m *recordingMailer) usecases.UserUsecase {
+	return usecases.NewUserUsecase(userRepo, socketPathRepo, repositories.NewPasswordResetRepository(db), loginAttemptRepo, repositories.NewRecoveryCodeRepository(db), repositories.NewSessionRepository(db), repositories.NewExternalIdentityRepository(db), m, nil)
+}
+
+// requestResetToken runs the forgot password flow and returns the token from the email
+func requestResetToken(t *testing.T, email string) string {
+	m := &recordingMailer{}
+	err := newUserUsecaseWithMailer(m).ForgotPassword(email)
+	assert.Nil(t, err)
+
+	match := resetTokenPattern.FindStringSubmatch(m.body)
+	assert.Len(t, match, 2)
+	return match[1]
+}
+
+func TestResetTokenSingleUse(t *testing.T) {
+	user := newTestUser(t, "reset")
+	token := requestResetToken(t, user.Email)
+
+	err := userUsecase.ResetPassword(token, "new-secret123")
+	assert.Nil(t, err)
+	_, err = userUsecase.Login(user.Email, "new-secret123", models.ClientInfo{})
+	assert.Nil(t, err)
+
+	err = userUsecase.ResetPassword(token, "another-secret123")
+	assert.NotNil(t, err)
+}
+
+func TestResetTokenExpires(t *testing.T) {
+	user := newTestUser(t, "reset")
+	token := requestResetToken(t, user.Email)
+
+	err := db.Model(&entities.PasswordResetToken{}).Where("user_id = ?", user.ID).Update("expires_at", time.Now().Add(-time.Minute)).Error
+	assert.Nil(t, err)
+
+	err = userUsecase.ResetPassword(token, "new-secret123")
+	assert.NotNil(t, err)
+	_, err = userUsecase.Login(user.Email, testPassword, models.ClientInfo{})
+	assert.Nil(t, err)
+}
+
+func TestForgotPasswordHidesMailerFailure(t *testing.T) {
+	user := newTestUser(t, "reset")
+	m := &recordingMailer{err: errors.New("smtp unavailable")}
+
+	// Same answer as for an unknown address
+	err := newUserUsecaseWithMailer(m).ForgotPassword(user.Email)
+	assert.Nil(t, err)
+	err = newUserUsecaseWithMailer(m).ForgotPassword("nobody@mail.com")
+	assert.Nil(t, err)
+}
@@ -0,0 +1,89 @@
+package usecase_test
+
+import (
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/usecases"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinnedMessageCap(t *testing.T) {
+	first := newTestUser(t, "first")
+	second := newTestUser(t, "second")
+	room, err := chatRoomUsecase.CreateRoom(first.ID, []string{first.ID, second.ID}, false, "")
+	assert.Nil(t, err)
+
+	var messageIDs []string
+	for i := 0; i < 11; i++ {
+		message := &entities.Message{ID: uuid.New().String(), ChatRoomID: room.ID, SenderID: first.ID, Content: fmt.Sprintf("Note %d", i)}
+		assert.Nil(t, messageUsecase.SaveMessage(message))
+		messageIDs = append(messageIDs, message.ID)
+	}
+
+	for _, v := range messageIDs[:10] {
+		_, err = chatRoomUsecase.PinMessage(second.ID, room.ID, v)
+		assert.Nil(t, err)
+	}
+
+	// Pinning an already pinned message is not counted again
+	_, err = chatRoomUsecase.PinMessage(first.ID, room.ID, messageIDs[0])
+	assert.Nil(t, err)
+
+	_, err = chatRoomUsecase.PinMessage(first.ID, room.ID, messageIDs[10])
+	assert.NotNil(t, err)
+
+	err = chatRoomUsecase.UnpinMessage(first.ID, room.ID, messageIDs[0])
+	assert.Nil(t, err)
+	_, err = chatRoomUsecase.PinMessage(first.ID, room.ID, messageIDs[10])
+	assert.Nil(t, err)
+
+	pins, err := chatRoomUsecase.GetPinnedMessages(first.ID, room.ID)
+	assert.Nil(t, err)
+	assert.Len(t, pins, 10)
+}
+
+func TestMemberCannotPinInGroup(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+	roomID := newTestGroup(t, owner, nil, member)
+
+	message := &entities.Message{ID: uuid.New().String(), ChatRoomID: roomID, SenderID: member.ID, Content: "Pin me"}
+	assert.Nil(t, messageUsecase.SaveMessage(message))
+
+	_, err := chatRoomUsecase.PinMessage(member.ID, roomID, message.ID)
+	assert.Equal(t, usecases.ErrRoomPermission, err)
+	_, err = chatRoomUsecase.PinMessage(owner.ID, roomID, message.ID)
+	assert.Nil(t, err)
+}
+
+func TestConcurrentPinsRespectCap(t *testing.T) {
+	first := newTestUser(t, "first")
+	second := newTestUser(t, "second")
+	room, err := chatRoomUsecase.CreateRoom(first.ID, []string{first.ID, second.ID}, false, "")
+	assert.Nil(t, err)
+
+	var messageIDs []string
+	for i := 0; i < 15; i++ {
+		message := &entities.Message{ID: uuid.New().String(), ChatRoomID: room.ID, SenderID: first.ID, Content: fmt.Sprintf("Note %d", i)}
+		assert.Nil(t, messageUsecase.SaveMessage(message))
+		messageIDs = append(messageIDs, message.ID)
+	}
+
+	var wg sync.WaitGroup
+	for _, v := range messageIDs {
+		wg.Add(1)
+		go func(messageID string) {
+			defer wg.Done()
+			chatRoomUsecase.PinMessage(second.ID, room.ID, messageID)
+		}(v)
+	}
+	wg.Wait()
+
+	pins, err := chatRoomUsecase.GetPinnedMessages(first.ID, room.ID)
+	assert.Nil(t, err)
+	assert.Len(t, pins, 10)
+}
@@ -0,0 +1,83 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/usecases"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func isStoredOnline(t *testing.T, userID string) bool {
+	user, err := userRepo.FindByID(userID)
+	assert.Nil(t, err)
+	return user.Online
+}
+
+// presenceStates returns the online flag of every presence event published for userID in order
+func (p *recordingPublisher) presenceStates(userID string) []bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var states []bool
+	for _, v := range p.events {
+		if response, ok := v.Data.(models.PresenceResponse); ok && response.UserID == userID {
+			states = append(states, response.Online)
+		}
+	}
+	return states
+}
+
+func TestPresenceStaysOnlineWhileAnotherConnectionIsOpen(t *testing.T) {
+	publisher := &recordingPublisher{}
+	presenceUsecase := usecases.NewPresenceUsecase(userRepo, publisher)
+
+	user := newTestUser(t, "presence")
+	contact := newTestUser(t, "contact")
+	_, err := chatRoomUsecase.CreateRoom(user.ID, []string{user.ID, contact.ID}, false, "")
+	assert.Nil(t, err)
+
+	event := func(status, connectionID string) models.PresenceEvent {
+		return models.PresenceEvent{UserID: user.ID, Status: status, ConnectionID: connectionID}
+	}
+
+	assert.Nil(t, presenceUsecase.HandlePresence(event(usecases.PresenceConnect, "phone")))
+	assert.Nil(t, presenceUsecase.HandlePresence(event(usecases.PresenceConnect, "laptop")))
+	assert.Nil(t, presenceUsecase.HandlePresence(event(usecases.PresenceHeartbeat, "phone")))
+	assert.True(t, isStoredOnline(t, user.ID))
+
+	// Closing one device keeps the user online and tells nobody
+	assert.Nil(t, presenceUsecase.HandlePresence(event(usecases.PresenceDisconnect, "phone")))
+	assert.True(t, isStoredOnline(t, user.ID))
+	assert.Equal(t, []bool{true}, publisher.presenceStates(user.ID))
+
+	assert.Nil(t, presenceUsecase.HandlePresence(event(usecases.PresenceDisconnect, "laptop")))
+	assert.False(t, isStoredOnline(t, user.ID))
+	assert.Equal(t, []bool{true, false}, publisher.presenceStates(user.ID))
+}
+
+func TestExpireStaleMarksSilentUsersOffline(t *testing.T) {
+	publisher := &recordingPublisher{}
+	presenceUsecase := usecases.NewPresenceUsecase(userRepo, publisher)
+
+	silent := newTestUser(t, "silent")
+	active := newTestUser(t, "active")
+	_, err := chatRoomUsecase.CreateRoom(silent.ID, []string{silent.ID, active.ID}, false, "")
+	assert.Nil(t, err)
+
+	for _, v := range []string{silent.ID, active.ID} {
+		err = presenceUsecase.HandlePresence(models.PresenceEvent{UserID: v, Status: usecases.PresenceConnect, ConnectionID: "socket"})
+		assert.Nil(t, err)
+	}
+
+	// The gateway of silent went away without a disconnect
+	assert.Nil(t, userRepo.UpdatePresence(silent.ID, true, time.Now().Add(-2*time.Minute)))
+
+	assert.Nil(t, presenceUsecase.ExpireStale())
+	assert.False(t, isStoredOnline(t, silent.ID))
+	assert.True(t, isStoredOnline(t, active.ID))
+	assert.Equal(t, []bool{true, false}, publisher.presenceStates(silent.ID))
+	assert.Equal(t, []bool{true}, publisher.presenceStates(active.ID))
+}
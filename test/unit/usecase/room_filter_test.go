@@ -0,0 +1,51 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func listRoomIDs(t *testing.T, userID string, filter models.RoomListFilter) []string {
+	rooms, _, err := chatRoomUsecase.GetRoomsForUser(userID, filter, 1, 50)
+	assert.Nil(t, err)
+
+	var roomIDs []string
+	for _, v := range rooms {
+		roomIDs = append(roomIDs, v.ID)
+	}
+	return roomIDs
+}
+
+func TestRoomListFilters(t *testing.T) {
+	viewer := newTestUser(t, "viewer")
+	friend := newTestUser(t, "friend")
+	other := newTestUser(t, "other")
+
+	direct, err := chatRoomUsecase.CreateRoom(viewer.ID, []string{viewer.ID, friend.ID}, false, "")
+	assert.Nil(t, err)
+	groupName := "Book club " + uuid.New().String()[:8]
+	group, err := chatRoomUsecase.CreateRoom(viewer.ID, []string{viewer.ID, other.ID}, true, groupName)
+	assert.Nil(t, err)
+	channel, err := chatRoomUsecase.CreateChannel(viewer.ID, models.CreateChannelRequest{Name: "News"})
+	assert.Nil(t, err)
+
+	assert.ElementsMatch(t, []string{direct.ID, group.ID, channel.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{}))
+	assert.Equal(t, []string{direct.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{Type: "direct"}))
+	assert.Equal(t, []string{group.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{Type: "group"}))
+	assert.Equal(t, []string{channel.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{Type: "channel"}))
+	assert.Equal(t, []string{group.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{Name: groupName}))
+	assert.Equal(t, []string{direct.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{Participant: "@" + friend.Username}))
+
+	// Only the direct room has a message the viewer has not read
+	assert.Nil(t, postMessage(direct.ID, friend.ID, "Are you coming?"))
+	assert.Equal(t, []string{direct.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{UnreadOnly: true}))
+
+	// Archived rooms leave the inbox and are only listed when asked for
+	_, err = chatRoomUsecase.ArchiveRoom(viewer.ID, group.ID, true)
+	assert.Nil(t, err)
+	assert.NotContains(t, listRoomIDs(t, viewer.ID, models.RoomListFilter{}), group.ID)
+	assert.Equal(t, []string{group.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{Archived: true}))
+}
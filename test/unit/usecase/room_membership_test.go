@@ -0,0 +1,153 @@
+package usecase_test
+
+import (
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/usecases"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestGroup creates a group owned by owner, with admin promoted when given
+func newTestGroup(t *testing.T, owner, admin *entities.User, members ...*entities.User) string {
+	userIDs := []string{owner.ID}
+	if admin != nil {
+		userIDs = append(userIDs, admin.ID)
+	}
+	for _, v := range members {
+		userIDs = append(userIDs, v.ID)
+	}
+	room, err := chatRoomUsecase.CreateRoom(owner.ID, userIDs, true, "Group "+uuid.New().String()[:8])
+	assert.Nil(t, err)
+
+	if admin != nil {
+		err = chatRoomUsecase.ChangeRole(owner.ID, room.ID, admin.ID, entities.RoleAdmin)
+		assert.Nil(t, err)
+	}
+	return room.ID
+}
+
+func roleOf(t *testing.T, roomID, userID string) string {
+	participants, err := chatRoomUsecase.FindUsersByRoomID(roomID)
+	assert.Nil(t, err)
+	for _, v := range participants {
+		if v.UserID == userID {
+			return v.Role
+		}
+	}
+	return ""
+}
+
+func TestCreateGroupWithTwoUsers(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+
+	room, err := chatRoomUsecase.CreateRoom(owner.ID, []string{owner.ID, member.ID}, true, "Two of us")
+	assert.Nil(t, err)
+	assert.True(t, room.IsGroup)
+	assert.Equal(t, entities.RoleOwner, roleOf(t, room.ID, owner.ID))
+}
+
+func TestAdminCannotRemoveOwner(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	admin := newTestUser(t, "admin")
+	roomID := newTestGroup(t, owner, admin)
+
+	err := chatRoomUsecase.RemoveMember(admin.ID, roomID, owner.ID)
+	assert.Equal(t, usecases.ErrOwnerProtection, err)
+}
+
+func TestAdminRemovesMember(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	admin := newTestUser(t, "admin")
+	member := newTestUser(t, "member")
+	roomID := newTestGroup(t, owner, admin, member)
+
+	err := chatRoomUsecase.RemoveMember(admin.ID, roomID, member.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "", roleOf(t, roomID, member.ID))
+}
+
+func TestMemberCannotRemoveMember(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+	other := newTestUser(t, "other")
+	roomID := newTestGroup(t, owner, nil, member, other)
+
+	err := chatRoomUsecase.RemoveMember(member.ID, roomID, other.ID)
+	assert.Equal(t, usecases.ErrRoomPermission, err)
+}
+
+func TestOnlyOwnerChangesRoles(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	admin := newTestUser(t, "admin")
+	member := newTestUser(t, "member")
+	roomID := newTestGroup(t, owner, admin, member)
+
+	err := chatRoomUsecase.ChangeRole(admin.ID, roomID, member.ID, entities.RoleAdmin)
+	assert.Equal(t, usecases.ErrRoomPermission, err)
+	assert.Equal(t, entities.RoleMember, roleOf(t, roomID, member.ID))
+
+	err = chatRoomUsecase.ChangeRole(owner.ID, roomID, admin.ID, entities.RoleMember)
+	assert.Nil(t, err)
+	assert.Equal(t, entities.RoleMember, roleOf(t, roomID, admin.ID))
+}
+
+func TestOwnerLeavingHandsOverOwnership(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	admin := newTestUser(t, "admin")
+	member := newTestUser(t, "member")
+	roomID := newTestGroup(t, owner, admin, member)
+
+	err := chatRoomUsecase.LeaveRoom(owner.ID, roomID)
+	assert.Nil(t, err)
+
+	// The admin is preferred over a member who joined at the same time
+	assert.Equal(t, "", roleOf(t, roomID, owner.ID))
+	assert.Equal(t, entities.RoleOwner, roleOf(t, roomID, admin.ID))
+	assert.Equal(t, entities.RoleMember, roleOf(t, roomID, member.ID))
+}
+
+func TestRemovedMemberCannotPost(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+	other := newTestUser(t, "other")
+	roomID := newTestGroup(t, owner, nil, member, other)
+
+	err := chatRoomUsecase.RemoveMember(owner.ID, roomID, member.ID)
+	assert.Nil(t, err)
+
+	err = messageUsecase.SaveMessage(&entities.Message{ID: uuid.New().String(), ChatRoomID: roomID, SenderID: member.ID, Content: "Still here?"})
+	assert.NotNil(t, err)
+}
+
+func TestOutsiderCannotPostInDirectRoom(t *testing.T) {
+	first := newTestUser(t, "first")
+	second := newTestUser(t, "second")
+	outsider := newTestUser(t, "outsider")
+
+	room, err := chatRoomUsecase.CreateRoom(first.ID, []string{first.ID, second.ID}, false, "")
+	assert.Nil(t, err)
+
+	err = messageUsecase.SaveMessage(&entities.Message{ID: uuid.New().String(), ChatRoomID: room.ID, SenderID: outsider.ID, Content: "Hi"})
+	assert.NotNil(t, err)
+}
+
+func TestAddingExistingParticipantIsIgnored(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+	roomID := newTestGroup(t, owner, nil, member)
+
+	// Two joins racing past the membership check both reach the insert
+	err := chatRoomRepo.AddParticipants([]entities.ChatRoomParticipant{
+		{ID: uuid.New().String(), ChatRoomID: roomID, UserID: member.ID, Role: entities.RoleMember, JoinedAt: time.Now()},
+	})
+	assert.Nil(t, err)
+
+	var count int64
+	err = db.Model(&entities.ChatRoomParticipant{}).Where("chat_room_id = ? AND user_id = ?", roomID, member.ID).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
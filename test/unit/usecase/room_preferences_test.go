@@ -0,0 +1,71 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinnedRoomLimit(t *testing.T) {
+	viewer := newTestUser(t, "viewer")
+
+	var roomIDs []string
+	for i := 0; i < 6; i++ {
+		room, err := chatRoomUsecase.CreateRoom(viewer.ID, []string{viewer.ID, newTestUser(t, "friend").ID}, false, "")
+		assert.Nil(t, err)
+		roomIDs = append(roomIDs, room.ID)
+	}
+
+	for _, v := range roomIDs[:5] {
+		room, err := chatRoomUsecase.PinRoom(viewer.ID, v, true)
+		assert.Nil(t, err)
+		assert.True(t, room.Pinned)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Pinning a pinned room again does not count against the limit
+	_, err := chatRoomUsecase.PinRoom(viewer.ID, roomIDs[0], true)
+	assert.Nil(t, err)
+	_, err = chatRoomUsecase.PinRoom(viewer.ID, roomIDs[5], true)
+	assert.NotNil(t, err)
+
+	// Pinned rooms come first, latest pin on top
+	listed := listRoomIDs(t, viewer.ID, models.RoomListFilter{})
+	assert.Equal(t, []string{roomIDs[4], roomIDs[3], roomIDs[2], roomIDs[1], roomIDs[0]}, listed[:5])
+
+	_, err = chatRoomUsecase.PinRoom(viewer.ID, roomIDs[0], false)
+	assert.Nil(t, err)
+	_, err = chatRoomUsecase.PinRoom(viewer.ID, roomIDs[5], true)
+	assert.Nil(t, err)
+}
+
+func TestArchivedRoomsOnlyListedWhenAsked(t *testing.T) {
+	viewer := newTestUser(t, "viewer")
+	friend := newTestUser(t, "friend")
+	room, err := chatRoomUsecase.CreateRoom(viewer.ID, []string{viewer.ID, friend.ID}, false, "")
+	assert.Nil(t, err)
+	kept, err := chatRoomUsecase.CreateRoom(viewer.ID, []string{viewer.ID, newTestUser(t, "other").ID}, false, "")
+	assert.Nil(t, err)
+
+	_, err = chatRoomUsecase.PinRoom(viewer.ID, room.ID, true)
+	assert.Nil(t, err)
+
+	// Archiving unpins the room and hides it from the inbox of the viewer only
+	archived, err := chatRoomUsecase.ArchiveRoom(viewer.ID, room.ID, true)
+	assert.Nil(t, err)
+	assert.True(t, archived.Archived)
+	assert.False(t, archived.Pinned)
+	assert.Equal(t, []string{kept.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{}))
+	assert.Equal(t, []string{room.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{Archived: true}))
+	assert.Contains(t, listRoomIDs(t, friend.ID, models.RoomListFilter{}), room.ID)
+
+	_, err = chatRoomUsecase.PinRoom(viewer.ID, room.ID, true)
+	assert.NotNil(t, err)
+
+	_, err = chatRoomUsecase.ArchiveRoom(viewer.ID, room.ID, false)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{room.ID, kept.ID}, listRoomIDs(t, viewer.ID, models.RoomListFilter{}))
+	assert.Empty(t, listRoomIDs(t, viewer.ID, models.RoomListFilter{Archived: true}))
+}
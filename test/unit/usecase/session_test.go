@@ -0,0 +1,111 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/domain/repositories"
+	"chat-be/internal/usecases"
+	"chat-be/package/helper"
+	"chat-be/package/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// loginDevice logs the user in and returns the token with the session it is bound to
+func loginDevice(t *testing.T, email, deviceName string) (string, string) {
+	response, err := userUsecase.Login(email, testPassword, models.ClientInfo{DeviceName: deviceName})
+	assert.Nil(t, err)
+	claims, err := helper.ValidateToken(response.Token)
+	assert.Nil(t, err)
+	return response.Token, claims.SessionID
+}
+
+func authorize(token string) int {
+	handler := middleware.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	r := httptest.NewRequest("GET", "/api/users/me", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestRevokedSessionRejected(t *testing.T) {
+	middleware.SetSessionValidator(sessionUsecase.ValidateSession)
+	defer middleware.SetSessionValidator(nil)
+
+	user := newTestUser(t, "session")
+	token, sessionID := loginDevice(t, user.Email, "Phone")
+	assert.Equal(t, http.StatusOK, authorize(token))
+
+	err := sessionUsecase.RevokeSession(user.ID, sessionID)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, authorize(token))
+}
+
+func TestRevokeOtherSessionsKeepsCurrent(t *testing.T) {
+	user := newTestUser(t, "session")
+	_, currentID := loginDevice(t, user.Email, "Laptop")
+	_, phoneID := loginDevice(t, user.Email, "Phone")
+	_, tabletID := loginDevice(t, user.Email, "Tablet")
+
+	err := sessionUsecase.RevokeOtherSessions(user.ID, currentID)
+	assert.Nil(t, err)
+
+	assert.Nil(t, sessionUsecase.ValidateSession(user.ID, currentID))
+	assert.Equal(t, usecases.ErrSessionRevoked, sessionUsecase.ValidateSession(user.ID, phoneID))
+	assert.Equal(t, usecases.ErrSessionRevoked, sessionUsecase.ValidateSession(user.ID, tabletID))
+
+	sessions, err := sessionUsecase.GetSessions(user.ID, currentID)
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 1)
+	assert.True(t, sessions[0].Current)
+}
+
+func TestRevokeSessionOfOtherUser(t *testing.T) {
+	user := newTestUser(t, "session")
+	other := newTestUser(t, "other")
+	_, sessionID := loginDevice(t, user.Email, "Phone")
+
+	err := sessionUsecase.RevokeSession(other.ID, sessionID)
+	assert.NotNil(t, err)
+	assert.Nil(t, sessionUsecase.ValidateSession(user.ID, sessionID))
+}
+
+func TestRevokedSessionRemovesPushTokens(t *testing.T) {
+	deviceTokenRepo := repositories.NewDeviceTokenRepository(db)
+	pushUsecase := usecases.NewPushUsecase(deviceTokenRepo, userRepo, chatRoomRepo, nil)
+
+	user := newTestUser(t, "session")
+	_, laptopID := loginDevice(t, user.Email, "Laptop")
+	_, phoneID := loginDevice(t, user.Email, "Phone")
+	_, tabletID := loginDevice(t, user.Email, "Tablet")
+
+	register := func(sessionID string) string {
+		device, err := pushUsecase.RegisterDevice(user.ID, sessionID, models.RegisterDeviceRequest{Token: uuid.New().String(), Platform: entities.PlatformAndroid})
+		assert.Nil(t, err)
+		return device.ID
+	}
+	laptopDevice := register(laptopID)
+	phoneDevice := register(phoneID)
+	tabletDevice := register(tabletID)
+
+	assert.Nil(t, sessionUsecase.RevokeSession(user.ID, phoneID))
+	device, err := deviceTokenRepo.FindByID(phoneDevice)
+	assert.Nil(t, err)
+	assert.Nil(t, device)
+
+	assert.Nil(t, sessionUsecase.RevokeOtherSessions(user.ID, laptopID))
+	device, err = deviceTokenRepo.FindByID(tabletDevice)
+	assert.Nil(t, err)
+	assert.Nil(t, device)
+
+	device, err = deviceTokenRepo.FindByID(laptopDevice)
+	assert.Nil(t, err)
+	assert.NotNil(t, device)
+}
@@ -4,6 +4,7 @@ import (
 	"chat-be/internal/config"
 	"chat-be/internal/database"
 	"chat-be/internal/domain/repositories"
+	"chat-be/internal/mailer"
 	"chat-be/internal/usecases"
 	"chat-be/package/logging"
 	"context"
@@ -11,13 +12,23 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 var (
-	userRepo        repositories.UserRepository
-	chatRoomRepo    repositories.ChatRoomRepository
-	chatRoomUsecase usecases.ChatRoomUsecase
-	ctx             context.Context
+	db                *gorm.DB
+	loginAttemptRepo  repositories.LoginAttemptRepository
+	userRepo          repositories.UserRepository
+	chatRoomRepo      repositories.ChatRoomRepository
+	socketPathRepo    repositories.SocketPathRepository
+	chatRoomUsecase   usecases.ChatRoomUsecase
+	userUsecase       usecases.UserUsecase
+	messageUsecase    usecases.MessageUsecase
+	botUsecase        usecases.BotUsecase
+	contactUsecase    usecases.ContactUsecase
+	moderationUsecase usecases.ModerationUsecase
+	sessionUsecase    usecases.SessionUsecase
+	ctx               context.Context
 )
 
 func TestMain(m *testing.M) {
@@ -31,12 +42,24 @@ func setup() {
 	config.LoadEnv()
 
 	// Initialize Database
-	db := database.InitDB()
+	db = database.InitDB()
 
 	chatRoomRepo = repositories.NewChatRoomRepository(db)
 	userRepo = repositories.NewUserRepository(db)
+	socketPathRepo = repositories.NewSocketPathRepository(db)
 
-	chatRoomUsecase = usecases.NewChatRoomUsecase(chatRoomRepo, userRepo)
+	chatRoomUsecase = usecases.NewChatRoomUsecase(chatRoomRepo, userRepo, repositories.NewMessageRepository(db), repositories.NewRoomInviteRepository(db), repositories.NewJoinRequestRepository(db), repositories.NewPinnedMessageRepository(db), repositories.NewContactRepository(db), repositories.NewBlockRepository(db), nil, nil, nil)
+	passwordResetRepo := repositories.NewPasswordResetRepository(db)
+	loginAttemptRepo = repositories.NewLoginAttemptRepository(db)
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(db)
+	sessionRepo := repositories.NewSessionRepository(db)
+	identityRepo := repositories.NewExternalIdentityRepository(db)
+	userUsecase = usecases.NewUserUsecase(userRepo, socketPathRepo, passwordResetRepo, loginAttemptRepo, recoveryCodeRepo, sessionRepo, identityRepo, mailer.NewOutboxMailer(os.TempDir()+"/wetalk-outbox.log"), nil)
+	messageUsecase = usecases.NewMessageUsecase(chatRoomRepo, repositories.NewMessageRepository(db), userRepo, repositories.NewBlockRepository(db), repositories.NewChannelFanOutRepository(db), nil, nil, nil)
+	botUsecase = usecases.NewBotUsecase(userRepo, socketPathRepo, repositories.NewAPIKeyRepository(db))
+	contactUsecase = usecases.NewContactUsecase(repositories.NewContactRepository(db), userRepo, repositories.NewBlockRepository(db), nil, nil)
+	moderationUsecase = usecases.NewModerationUsecase(repositories.NewBlockRepository(db), repositories.NewReportRepository(db), repositories.NewContactRepository(db), userRepo, repositories.NewMessageRepository(db), chatRoomRepo)
+	sessionUsecase = usecases.NewSessionUsecase(sessionRepo)
 	requestID := uuid.New().String()
 	ctx = context.WithValue(context.Background(), logging.RequestIDKey, requestID)
 }
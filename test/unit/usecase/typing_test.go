@@ -0,0 +1,81 @@
+package usecase_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/usecases"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingPublisher keeps published events instead of sending them to Kafka
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []usecases.Event
+}
+
+func (p *recordingPublisher) Publish(event usecases.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+// typingStates returns the typing flag of every published typing event in order
+func (p *recordingPublisher) typingStates() []bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var states []bool
+	for _, v := range p.events {
+		if response, ok := v.Data.(models.TypingResponse); ok {
+			states = append(states, response.Typing)
+		}
+	}
+	return states
+}
+
+func TestTypingStartIsThrottled(t *testing.T) {
+	publisher := &recordingPublisher{}
+	typingUsecase := usecases.NewTypingUsecase(chatRoomRepo, publisher)
+
+	user := newTestUser(t, "typer")
+	roomID := newTestGroup(t, user, nil, newTestUser(t, "reader"))
+	start := models.TypingEvent{UserID: user.ID, RoomID: roomID, Typing: true}
+	stop := models.TypingEvent{UserID: user.ID, RoomID: roomID, Typing: false}
+
+	assert.Nil(t, typingUsecase.HandleTyping(start))
+	assert.Nil(t, typingUsecase.HandleTyping(start))
+	assert.Nil(t, typingUsecase.HandleTyping(start))
+	assert.Equal(t, []bool{true}, publisher.typingStates())
+
+	assert.Nil(t, typingUsecase.HandleTyping(stop))
+	assert.Nil(t, typingUsecase.HandleTyping(stop))
+	assert.Equal(t, []bool{true, false}, publisher.typingStates())
+}
+
+func TestTypingExpiresOnce(t *testing.T) {
+	publisher := &recordingPublisher{}
+	typingUsecase := usecases.NewTypingUsecase(chatRoomRepo, publisher)
+
+	user := newTestUser(t, "typer")
+	roomID := newTestGroup(t, user, nil, newTestUser(t, "reader"))
+	start := models.TypingEvent{UserID: user.ID, RoomID: roomID, Typing: true}
+	stop := models.TypingEvent{UserID: user.ID, RoomID: roomID, Typing: false}
+
+	// The timer of the first start must not clear the second one
+	assert.Nil(t, typingUsecase.HandleTyping(start))
+	assert.Nil(t, typingUsecase.HandleTyping(stop))
+	assert.Nil(t, typingUsecase.HandleTyping(start))
+	assert.Equal(t, []bool{true, false, true}, publisher.typingStates())
+
+	time.Sleep(7 * time.Second)
+	assert.Equal(t, []bool{true, false, true, false}, publisher.typingStates())
+
+	// Expired state is gone, so the next start is forwarded right away
+	assert.Nil(t, typingUsecase.HandleTyping(start))
+	assert.Equal(t, []bool{true, false, true, false, true}, publisher.typingStates())
+}
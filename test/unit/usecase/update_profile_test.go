@@ -0,0 +1,33 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateProfile(t *testing.T) {
+	displayName := "Azis"
+	bio := "Hello there"
+	request := models.UpdateProfileRequest{
+		DisplayName: &displayName,
+		Bio:         &bio,
+	}
+
+	profile, err := userUsecase.UpdateProfile("ccffa72d-8a9f-463d-8f76-aa2edbba7b8b", request)
+	assert.Nil(t, err)
+	assert.Equal(t, displayName, profile.DisplayName)
+	fmt.Println(profile)
+}
+
+func TestUpdateProfileEmptyUsername(t *testing.T) {
+	username := " "
+	request := models.UpdateProfileRequest{
+		Username: &username,
+	}
+
+	_, err := userUsecase.UpdateProfile("ccffa72d-8a9f-463d-8f76-aa2edbba7b8b", request)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,53 @@
+package usecase_test
+
+import (
+	"chat-be/internal/delivery/http/models"
+	"chat-be/internal/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminUpdatesRoom(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	admin := newTestUser(t, "admin")
+	roomID := newTestGroup(t, owner, admin)
+
+	name := "Weekend trip"
+	description := "Plans for Saturday"
+	room, err := chatRoomUsecase.UpdateRoom(admin.ID, roomID, models.UpdateRoomRequest{Name: &name, Description: &description})
+	assert.Nil(t, err)
+	assert.Equal(t, name, room.Name)
+	assert.Equal(t, description, room.Description)
+}
+
+func TestMemberCannotUpdateRoom(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+	roomID := newTestGroup(t, owner, nil, member)
+
+	name := "Hijacked"
+	_, err := chatRoomUsecase.UpdateRoom(member.ID, roomID, models.UpdateRoomRequest{Name: &name})
+	assert.Equal(t, usecases.ErrRoomPermission, err)
+}
+
+func TestDirectRoomCannotBeUpdated(t *testing.T) {
+	first := newTestUser(t, "first")
+	second := newTestUser(t, "second")
+	room, err := chatRoomUsecase.CreateRoom(first.ID, []string{first.ID, second.ID}, false, "")
+	assert.Nil(t, err)
+
+	name := "Renamed"
+	_, err = chatRoomUsecase.UpdateRoom(first.ID, room.ID, models.UpdateRoomRequest{Name: &name})
+	assert.Equal(t, usecases.ErrNotGroupRoom, err)
+}
+
+func TestUpdateRoomEmptyName(t *testing.T) {
+	owner := newTestUser(t, "owner")
+	member := newTestUser(t, "member")
+	roomID := newTestGroup(t, owner, nil, member)
+
+	name := " "
+	_, err := chatRoomUsecase.UpdateRoom(owner.ID, roomID, models.UpdateRoomRequest{Name: &name})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,183 @@
+package webhook_test
+
+import (
+	"chat-be/internal/domain/entities"
+	"chat-be/internal/webhook"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryWebhookRepo keeps deliveries in memory so the worker can run without a database
+type memoryWebhookRepo struct {
+	mu         sync.Mutex
+	deliveries map[string]*entities.WebhookDelivery
+}
+
+func newMemoryWebhookRepo(deliveries ...entities.WebhookDelivery) *memoryWebhookRepo {
+	repo := &memoryWebhookRepo{deliveries: map[string]*entities.WebhookDelivery{}}
+	for i := range deliveries {
+		repo.deliveries[deliveries[i].ID] = &deliveries[i]
+	}
+	return repo
+}
+
+func (r *memoryWebhookRepo) Create(webhook *entities.Webhook) error { return nil }
+
+func (r *memoryWebhookRepo) FindByID(id string) (*entities.Webhook, error) { return nil, nil }
+
+func (r *memoryWebhookRepo) FindByRoomID(roomID string) ([]entities.Webhook, error) {
+	return nil, nil
+}
+
+func (r *memoryWebhookRepo) Delete(id string) error { return nil }
+
+func (r *memoryWebhookRepo) CreateDeliveries(deliveries []entities.WebhookDelivery) error {
+	return nil
+}
+
+func (r *memoryWebhookRepo) FindDeliveriesByWebhookID(webhookID string, offset int, limit int) ([]entities.WebhookDelivery, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *memoryWebhookRepo) ClaimDueDeliveries(limit int, lease time.Duration) ([]entities.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []entities.WebhookDelivery
+	for _, v := range r.deliveries {
+		if v.Status == entities.WebhookDeliveryPending && !v.NextAttemptAt.After(time.Now()) {
+			due = append(due, *v)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryWebhookRepo) UpdateDelivery(delivery *entities.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+func (r *memoryWebhookRepo) get(id string) entities.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.deliveries[id]
+}
+
+func pendingDelivery(id string, hook entities.Webhook) entities.WebhookDelivery {
+	return entities.WebhookDelivery{
+		ID:            id,
+		WebhookID:     hook.ID,
+		Webhook:       hook,
+		Event:         entities.WebhookEventMessageNew,
+		Payload:       `{"event":"message.new"}`,
+		Status:        entities.WebhookDeliveryPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"message.new"}`)
+	signature := webhook.Sign("whsec_test", 1700000000, body)
+
+	assert.True(t, webhook.VerifySignature("whsec_test", 1700000000, body, signature))
+	assert.False(t, webhook.VerifySignature("whsec_other", 1700000000, body, signature))
+	assert.False(t, webhook.VerifySignature("whsec_test", 1700000001, body, signature))
+	assert.False(t, webhook.VerifySignature("whsec_test", 1700000000, []byte(`{}`), signature))
+}
+
+func TestWorkerDeliversSignedPayload(t *testing.T) {
+	received := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.TimestampHeader), 10, 64)
+
+		assert.Equal(t, entities.WebhookEventMessageNew, r.Header.Get(webhook.EventHeader))
+		assert.Equal(t, "delivery-1", r.Header.Get(webhook.DeliveryHeader))
+		received <- webhook.VerifySignature("whsec_test", timestamp, body, r.Header.Get(webhook.SignatureHeader))
+	}))
+	defer server.Close()
+
+	hook := entities.Webhook{ID: "hook-1", URL: server.URL, Secret: "whsec_test"}
+	repo := newMemoryWebhookRepo(pendingDelivery("delivery-1", hook))
+	worker := webhook.NewWorker(repo)
+
+	worker.ProcessDue()
+
+	assert.True(t, <-received)
+	delivery := repo.get("delivery-1")
+	assert.Equal(t, entities.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.LastStatusCode)
+	assert.NotNil(t, delivery.DeliveredAt)
+}
+
+func TestWorkerRetriesWithBackoffThenFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	hook := entities.Webhook{ID: "hook-1", URL: server.URL, Secret: "whsec_test"}
+	repo := newMemoryWebhookRepo(pendingDelivery("delivery-1", hook))
+	worker := webhook.NewWorker(repo)
+	worker.MaxAttempts = 2
+
+	worker.ProcessDue()
+
+	delivery := repo.get("delivery-1")
+	assert.Equal(t, entities.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusInternalServerError, delivery.LastStatusCode)
+	assert.True(t, delivery.NextAttemptAt.After(time.Now()))
+
+	// Not due yet, nothing is sent
+	worker.ProcessDue()
+	assert.Equal(t, 1, repo.get("delivery-1").Attempts)
+
+	delivery.NextAttemptAt = time.Now().Add(-time.Second)
+	repo.UpdateDelivery(&delivery)
+	worker.ProcessDue()
+
+	delivery = repo.get("delivery-1")
+	assert.Equal(t, entities.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+}
+
+func TestRetryDelayDoubles(t *testing.T) {
+	assert.Equal(t, 2*webhook.RetryDelay(1), webhook.RetryDelay(2))
+	assert.Equal(t, 4*webhook.RetryDelay(1), webhook.RetryDelay(3))
+}
+
+func TestWorkerSendsBatchInParallel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	hook := entities.Webhook{ID: "hook-1", URL: server.URL, Secret: "whsec_test"}
+	var deliveries []entities.WebhookDelivery
+	for i := 0; i < 10; i++ {
+		deliveries = append(deliveries, pendingDelivery("delivery-"+strconv.Itoa(i), hook))
+	}
+	repo := newMemoryWebhookRepo(deliveries...)
+	worker := webhook.NewWorker(repo)
+
+	started := time.Now()
+	worker.ProcessDue()
+
+	// Sent one after another this would take two seconds
+	assert.Less(t, time.Since(started), time.Second)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, entities.WebhookDeliverySucceeded, repo.get("delivery-"+strconv.Itoa(i)).Status)
+	}
+}